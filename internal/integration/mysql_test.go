@@ -750,7 +750,7 @@ func TestMySQL_HCL_Realm(t *testing.T) {
 	myRun(t, func(t *myTest) {
 		t.dropSchemas("second")
 		realm := t.loadRealm()
-		hcl, err := mysql.MarshalHCL(realm)
+		hcl, err := mysql.MarshalHCL.MarshalSpec(realm)
 		require.NoError(t, err)
 		wa := string(hcl) + `
 schema "second" {
@@ -823,12 +823,12 @@ schema "users" {
 	myRun(t, func(t *myTest) {
 		t.dropSchemas("financial", "users")
 		realm := t.loadRealm()
-		hcl, err := mysql.MarshalHCL(realm)
+		hcl, err := mysql.MarshalHCL.MarshalSpec(realm)
 		require.NoError(t, err)
 		t.applyRealmHcl(string(hcl) + "\n" + expected)
 		realm, err = t.drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Schemas: []string{"users", "financial"}})
 		require.NoError(t, err)
-		actual, err := mysql.MarshalHCL(realm)
+		actual, err := mysql.MarshalHCL.MarshalSpec(realm)
 		require.NoError(t, err)
 		require.Equal(t, expected, string(actual))
 	})
@@ -851,7 +851,7 @@ create table atlas_defaults
 		_, err := t.db.Exec(ddl)
 		require.NoError(t, err)
 		realm := t.loadRealm()
-		spec, err := mysql.MarshalHCL(realm.Schemas[0])
+		spec, err := mysql.MarshalHCL.MarshalSpec(realm.Schemas[0])
 		require.NoError(t, err)
 		var s schema.Realm
 		err = mysql.EvalHCLBytes(spec, &s, nil)
@@ -1539,7 +1539,7 @@ func (t *myTest) defaultAttrs() []schema.Attr {
 }
 
 func (t *myTest) hclDriftTest(n string, realm *schema.Realm, expected schema.Table) {
-	spec, err := mysql.MarshalHCL(realm.Schemas[0])
+	spec, err := mysql.MarshalHCL.MarshalSpec(realm.Schemas[0])
 	require.NoError(t, err)
 	t.dropTables(n)
 	t.applyHcl(string(spec))
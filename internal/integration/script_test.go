@@ -310,7 +310,7 @@ func (t *myTest) cmdCmpHCL(ts *testscript.TestScript, _ bool, args []string) {
 	cmdCmpHCL(ts, args, func(name string) (string, error) {
 		s, err := t.drv.InspectSchema(context.Background(), name, nil)
 		ts.Check(err)
-		buf, err := mysql.MarshalHCL(s)
+		buf, err := mysql.MarshalHCL.MarshalSpec(s)
 		require.NoError(t, err)
 		return string(buf), nil
 	}, func(s string) string {
@@ -460,7 +460,7 @@ func TestTiDB_HCL_Realm(t *testing.T) {
 	tidbRun(t, func(t *myTest) {
 		t.dropSchemas("second")
 		realm := t.loadRealm()
-		hcl, err := mysql.MarshalHCL(realm)
+		hcl, err := mysql.MarshalHCL.MarshalSpec(realm)
 		require.NoError(t, err)
 		wa := string(hcl) + `
 schema "second" {
@@ -493,7 +493,7 @@ create table atlas_defaults
 		_, err := t.db.Exec(ddl)
 		require.NoError(t, err)
 		realm := t.loadRealm()
-		spec, err := mysql.MarshalHCL(realm.Schemas[0])
+		spec, err := mysql.MarshalHCL.MarshalSpec(realm.Schemas[0])
 		require.NoError(t, err)
 		var s schema.Realm
 		err = mysql.EvalHCLBytes(spec, &s, nil)
@@ -6,6 +6,7 @@ package schemahcl
 
 import (
 	"fmt"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
@@ -42,7 +43,10 @@ func (s *State) setInputVals(ctx *hcl.EvalContext, body hcl.Body, input map[stri
 	}
 	ctxVars := make(map[string]cty.Value)
 	for _, v := range doc.Vars {
-		var vv cty.Value
+		var (
+			vv cty.Value
+			ev = s.lookupEnv(v.Name)
+		)
 		switch iv, ok := input[v.Name]; {
 		case !v.Type.Type().IsCapsuleType():
 			return fmt.Errorf(
@@ -51,6 +55,8 @@ func (s *State) setInputVals(ctx *hcl.EvalContext, body hcl.Body, input map[stri
 			)
 		case ok:
 			vv = iv
+		case ev != cty.NilVal:
+			vv = ev
 		case v.Default != cty.NilVal:
 			vv = v.Default
 		default:
@@ -72,6 +78,18 @@ func (s *State) setInputVals(ctx *hcl.EvalContext, body hcl.Body, input map[stri
 	return nil
 }
 
+// lookupEnv returns the value of the environment variable for the given input
+// variable, or cty.NilVal if it does not exist or no prefix was configured.
+func (s *State) lookupEnv(name string) cty.Value {
+	if s.config.envPrefix == "" {
+		return cty.NilVal
+	}
+	if v, ok := os.LookupEnv(s.config.envPrefix + name); ok {
+		return cty.StringVal(v)
+	}
+	return cty.NilVal
+}
+
 // evalReferences evaluates data blocks.
 func (s *State) evalReferences(ctx *hcl.EvalContext, body *hclsyntax.Body) error {
 	type node struct {
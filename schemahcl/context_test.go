@@ -405,6 +405,72 @@ variadic = enum("a","b","c")
 	require.EqualValues(t, f, string(after))
 }
 
+func TestWithTypes_Namespaced(t *testing.T) {
+	f := `first  = int
+second = app.int
+third  = app.uuid
+`
+	s := New(
+		WithTypes(
+			[]*TypeSpec{
+				{Name: "int", T: "int"},
+				{Name: "app.int", T: "app_int"},
+				{Name: "app.uuid", T: "app_uuid"},
+			},
+		),
+	)
+	var test struct {
+		First  *Type `spec:"first"`
+		Second *Type `spec:"second"`
+		Third  *Type `spec:"third"`
+	}
+	require.NoError(t, s.EvalBytes([]byte(f), &test, nil))
+	require.EqualValues(t, "int", test.First.T)
+	require.EqualValues(t, "app_int", test.Second.T)
+	require.EqualValues(t, "app_uuid", test.Third.T)
+	after, err := s.MarshalSpec(&test)
+	require.NoError(t, err)
+	require.EqualValues(t, f, string(after))
+}
+
+func TestWithTypes_NamespacedInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		types []*TypeSpec
+		err   string
+	}{
+		{
+			types: []*TypeSpec{{Name: "app.varchar", T: "app_varchar", Attributes: []*TypeAttr{{Name: "size", Kind: reflect.Int, Required: true}}}},
+			err:   `schemahcl: invalid typespec "app.varchar": namespaced type cannot have required attributes`,
+		},
+		{
+			types: []*TypeSpec{{Name: "string.uuid", T: "string_uuid"}},
+			err:   `schemahcl: namespace of type "string.uuid" collides with type "string"`,
+		},
+		{
+			types: []*TypeSpec{{Name: "app", T: "app"}, {Name: "app.uuid", T: "app_uuid"}},
+			err:   `schemahcl: namespace of type "app.uuid" collides with type "app"`,
+		},
+		{
+			types: []*TypeSpec{{Name: "var.uuid", T: "var_uuid"}},
+			err:   `schemahcl: namespace of type "var.uuid" is a reserved identifier`,
+		},
+	} {
+		s := New(WithTypes(tt.types))
+		require.EqualError(t, s.EvalBytes([]byte(`a = 1`), &struct{}{}, nil), tt.err)
+		require.EqualError(t, s.EvalJSON([]byte(`{}`), &struct{}{}), tt.err)
+	}
+	// Types with required arguments are registered as functions, and do not collide.
+	s := New(WithTypes([]*TypeSpec{
+		{Name: "app", T: "app", Attributes: []*TypeAttr{{Name: "size", Kind: reflect.Int, Required: true}}},
+		{Name: "app.uuid", T: "app_uuid"},
+	}))
+	var v struct {
+		A *Type `spec:"a"`
+	}
+	require.NoError(t, s.EvalBytes([]byte(`a = app.uuid`), &v, nil))
+	require.Equal(t, "app_uuid", v.A.T)
+}
+
 func TestEmptyStrSQL(t *testing.T) {
 	s := New(WithTypes(nil))
 	h := `x = sql("")`
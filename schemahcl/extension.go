@@ -210,12 +210,11 @@ func (r *Resource) as(target any) error {
 		return nil
 	}
 	extras := rem.Remain()
-	for attrName := range existingAttrs {
-		attr, ok := r.Attr(attrName)
-		if !ok {
-			return fmt.Errorf("schemahcl: expected attr %q to exist", attrName)
+	// Extra attributes are kept in their resource order.
+	for _, attr := range r.Attrs {
+		if _, ok := existingAttrs[attr.K]; ok {
+			extras.SetAttr(attr)
 		}
-		extras.SetAttr(attr)
 	}
 	for childType := range existingChildren {
 		children := childrenOfType(r, childType)
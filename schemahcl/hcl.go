@@ -7,6 +7,7 @@ package schemahcl
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"reflect"
 	"sort"
 	"strconv"
@@ -53,6 +54,31 @@ func (s *State) MarshalSpec(v any) ([]byte, error) {
 	return s.encode(r)
 }
 
+// MarshalSpecTo is like MarshalSpec, but marshals the given values one by one and
+// writes them to w. The header comment (if configured) is written once, before the
+// first value, instead of being prepended to each one of them.
+func (s *State) MarshalSpecTo(w io.Writer, vs ...any) error {
+	var buf bytes.Buffer
+	s.writeHeader(&buf)
+	if _, err := w.Write(s.newline(buf.Bytes())); err != nil {
+		return err
+	}
+	for _, v := range vs {
+		r := &Resource{}
+		if err := r.Scan(v); err != nil {
+			return fmt.Errorf("schemahcl: failed scanning %T to resource: %w", v, err)
+		}
+		b, err := s.encodeBody(r)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(s.newline(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // EvalFiles evaluates the files in the provided paths using the input variables and
 // populates v with the result.
 func (s *State) EvalFiles(paths []string, v any, input map[string]cty.Value) error {
@@ -68,6 +94,9 @@ func (s *State) EvalFiles(paths []string, v any, input map[string]cty.Value) err
 // Eval evaluates the parsed HCL documents using the input variables and populates v
 // using the result.
 func (s *State) Eval(parsed *hclparse.Parser, v any, input map[string]cty.Value) error {
+	if s.config.err != nil {
+		return s.config.err
+	}
 	ctx := s.config.newCtx()
 	reg := &blockDef{
 		fields:   make(map[string]struct{}),
@@ -351,6 +380,19 @@ func (s *State) toResource(ctx *hcl.EvalContext, block *hclsyntax.Block, scope [
 // encode the given *schemahcl.Resource into a byte slice containing an Atlas HCL
 // document representing it.
 func (s *State) encode(r *Resource) ([]byte, error) {
+	b, err := s.encodeBody(r)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	s.writeHeader(&buf)
+	buf.Write(b)
+	return s.newline(buf.Bytes()), nil
+}
+
+// encodeBody encodes the given *schemahcl.Resource without the header comment
+// and without replacing the line endings of the document.
+func (s *State) encodeBody(r *Resource) ([]byte, error) {
 	f := hclwrite.NewFile()
 	body := f.Body()
 	// If the resource has a Type then it is rendered as an HCL block.
@@ -368,9 +410,64 @@ func (s *State) encode(r *Resource) ([]byte, error) {
 			return nil, err
 		}
 	}
-	var buf bytes.Buffer
-	_, err := f.WriteTo(&buf)
-	return buf.Bytes(), err
+	return s.indent(f.Bytes()), nil
+}
+
+// newline replaces the line endings of the marshaled
+// document (LF) with the configured one (if exists).
+func (s *State) newline(b []byte) []byte {
+	if s.config.newline == "" || s.config.newline == "\n" {
+		return b
+	}
+	return bytes.ReplaceAll(b, []byte("\n"), []byte(s.config.newline))
+}
+
+// indent replaces the default indentation of the formatted
+// document (2 spaces) with the configured one (if exists). Only
+// lines that start with a token (e.g. a block, an attribute or a
+// closing brace) are re-indented, and the content of multi-line
+// values, such as heredoc strings, is kept as-is.
+func (s *State) indent(b []byte) []byte {
+	if s.config.indent == "" || s.config.indent == "  " {
+		return b
+	}
+	tokens, diags := hclsyntax.LexConfig(b, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return b
+	}
+	starts := make(map[int]bool)
+	for i, t := range tokens {
+		// Line comments include their terminating newline.
+		if i == 0 || tokens[i-1].Type == hclsyntax.TokenNewline || tokens[i-1].Type == hclsyntax.TokenComment && bytes.HasSuffix(tokens[i-1].Bytes, []byte("\n")) {
+			starts[t.Range.Start.Line] = true
+		}
+	}
+	lines := bytes.Split(b, []byte("\n"))
+	for i, l := range lines {
+		if !starts[i+1] {
+			continue
+		}
+		// Formatted documents are indented with 2 spaces per level.
+		n := (len(l) - len(bytes.TrimLeft(l, " "))) / 2
+		if n > 0 {
+			lines[i] = append([]byte(strings.Repeat(s.config.indent, n)), l[n*2:]...)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// writeHeader writes the configured header comment (if exists) to the buffer.
+func (s *State) writeHeader(buf *bytes.Buffer) {
+	if s.config.header == "" {
+		return
+	}
+	for _, l := range strings.Split(strings.TrimRight(s.config.header, "\n"), "\n") {
+		if l = strings.TrimSpace(l); !strings.HasPrefix(l, "//") && !strings.HasPrefix(l, "#") {
+			l = strings.TrimSpace("// " + l)
+		}
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
 }
 
 func (s *State) writeResource(b *Resource, body *hclwrite.Body) error {
@@ -401,29 +498,26 @@ func labels(r *Resource) []string {
 }
 
 func (s *State) writeAttr(attr *Attr, body *hclwrite.Body) error {
+	if s.config.omit[attr.K] {
+		return nil
+	}
 	switch {
 	case attr.IsRef():
 		v, err := attr.Ref()
 		if err != nil {
 			return err
 		}
-		body.SetAttributeRaw(attr.K, hclRefTokens(v))
+		body.SetAttributeRaw(attr.K, hclRefTokens(v, s.config.quote))
 	case attr.IsType():
 		t, err := attr.Type()
 		if err != nil {
 			return err
 		}
 		if t.IsRef {
-			body.SetAttributeRaw(attr.K, hclRefTokens(t.T))
-			break
-		}
-		spec, ok := s.findTypeSpec(t.T)
-		if !ok {
-			v := fmt.Sprintf("sql(%q)", t.T)
-			body.SetAttributeRaw(attr.K, hclRawTokens(v))
+			body.SetAttributeRaw(attr.K, hclRefTokens(t.T, s.config.quote))
 			break
 		}
-		st, err := hclType(spec, t)
+		st, err := s.typeExpr(t)
 		if err != nil {
 			return err
 		}
@@ -449,7 +543,7 @@ func (s *State) writeAttr(attr *Attr, body *hclwrite.Body) error {
 				if !ok {
 					return fmt.Errorf("unsupported capsule type: %v", v.Type())
 				}
-				tokens = append(tokens, hclRefTokens(ref.V))
+				tokens = append(tokens, hclRefTokens(ref.V, s.config.quote))
 			} else {
 				tokens = append(tokens, hclwrite.TokensForValue(v))
 			}
@@ -470,6 +564,16 @@ func (s *State) findTypeSpec(t string) (*TypeSpec, bool) {
 	return nil, false
 }
 
+// typeExpr returns the HCL expression of the given type. Types
+// that are not registered are written using the sql() function.
+func (s *State) typeExpr(t *Type) (string, error) {
+	spec, ok := s.findTypeSpec(t.T)
+	if !ok {
+		return fmt.Sprintf("sql(%q)", t.T), nil
+	}
+	return hclType(spec, t)
+}
+
 func hclType(spec *TypeSpec, typ *Type) (string, error) {
 	if spec.Format != nil {
 		return spec.Format(typ)
@@ -523,17 +627,21 @@ func findAttr(attrs []*Attr, k string) (*Attr, bool) {
 	return nil, false
 }
 
-func hclRefTokens(ref string) hclwrite.Tokens {
+// hclRefTokens returns the tokens of the given reference. Names in the reference
+// are quoted if they are not valid identifiers, or if quote is set. Kinds (the
+// elements prefixed with $) are never quoted.
+func hclRefTokens(ref string, quote bool) hclwrite.Tokens {
 	var t []*hclwrite.Token
 	for i, s := range strings.Split(ref, ".") {
 		// Ignore the first $ as token for reference.
-		if len(s) > 1 && s[0] == '$' {
+		kind := len(s) > 1 && s[0] == '$'
+		if kind {
 			s = s[1:]
 		}
 		switch {
 		case i == 0:
 			t = append(t, hclRawTokens(s)...)
-		case hclsyntax.ValidIdentifier(s):
+		case hclsyntax.ValidIdentifier(s) && (kind || !quote):
 			t = append(t, &hclwrite.Token{
 				Type:  hclsyntax.TokenDot,
 				Bytes: []byte{'.'},
@@ -43,6 +43,173 @@ bl = [true, false]
 	require.EqualValues(t, f, string(marshal))
 }
 
+func TestWithHeader(t *testing.T) {
+	type doc struct {
+		Name string `spec:"name"`
+	}
+	s := New(WithHeader("Generated by Atlas; do not edit.\n\n# Version: 1"))
+	buf, err := s.MarshalSpec(&doc{Name: "a8m"})
+	require.NoError(t, err)
+	require.Equal(t, `// Generated by Atlas; do not edit.
+//
+# Version: 1
+name = "a8m"
+`, string(buf))
+	var d doc
+	require.NoError(t, s.EvalBytes(buf, &d, nil))
+	require.Equal(t, "a8m", d.Name)
+}
+
+func TestWithIndent(t *testing.T) {
+	type (
+		Child struct {
+			Name string `spec:",name"`
+			Attr string `spec:"attr"`
+		}
+		doc struct {
+			Children []*Child `spec:"child"`
+		}
+	)
+	d := &doc{Children: []*Child{{Name: "c", Attr: "a"}}}
+	buf, err := New(WithIndent("\t")).MarshalSpec(d)
+	require.NoError(t, err)
+	require.Equal(t, "child \"c\" {\n\tattr = \"a\"\n}\n", string(buf))
+	buf, err = New(WithIndent("    ")).MarshalSpec(d)
+	require.NoError(t, err)
+	require.Equal(t, "child \"c\" {\n    attr = \"a\"\n}\n", string(buf))
+	var got doc
+	require.NoError(t, New().EvalBytes(buf, &got, nil))
+	require.Equal(t, d, &got)
+
+	// Lines of multi-line values are re-indented only if they start with a token.
+	buf, err = New(WithIndent("\t")).encode(&Resource{Children: []*Resource{{
+		Type: "child",
+		Name: "c",
+		Attrs: []*Attr{
+			{K: "obj", V: cty.ObjectVal(map[string]cty.Value{"a": cty.StringVal("a"), "b": cty.StringVal("b")})},
+		},
+	}}})
+	require.NoError(t, err)
+	require.Equal(t, "child \"c\" {\n\tobj = {\n\t\ta = \"a\"\n\t\tb = \"b\"\n\t}\n}\n", string(buf))
+	const heredoc = `child "c" {
+  query = <<-EOS
+    SELECT 1
+      FROM t
+  EOS
+  // comment
+  attr = "a"
+}
+`
+	require.Equal(t, `child "c" {
+	query = <<-EOS
+    SELECT 1
+      FROM t
+  EOS
+	// comment
+	attr = "a"
+}
+`, string(New(WithIndent("\t")).indent([]byte(heredoc))))
+}
+
+func TestWithNewline(t *testing.T) {
+	type (
+		Child struct {
+			Name string `spec:",name"`
+			Attr string `spec:"attr"`
+		}
+		doc struct {
+			Children []*Child `spec:"child"`
+		}
+	)
+	d := &doc{Children: []*Child{{Name: "c", Attr: "a\nb"}}}
+	lf, err := New(WithHeader("Generated")).MarshalSpec(d)
+	require.NoError(t, err)
+	require.Equal(t, "// Generated\nchild \"c\" {\n  attr = \"a\\nb\"\n}\n", string(lf))
+	crlf, err := New(WithHeader("Generated"), WithNewline("\r\n")).MarshalSpec(d)
+	require.NoError(t, err)
+	require.Equal(t, "// Generated\r\nchild \"c\" {\r\n  attr = \"a\\nb\"\r\n}\r\n", string(crlf))
+	for _, buf := range [][]byte{lf, crlf} {
+		var got doc
+		require.NoError(t, New().EvalBytes(buf, &got, nil))
+		require.Equal(t, d, &got)
+	}
+}
+
+func TestWithEnvVars(t *testing.T) {
+	type doc struct {
+		Name  string `spec:"name"`
+		Count int    `spec:"count"`
+	}
+	const f = `
+variable "name" {
+  type = string
+}
+variable "count" {
+  type    = number
+  default = 1
+}
+name  = var.name
+count = var.count
+`
+	t.Setenv("TEST_VAR_name", "a8m")
+	t.Setenv("TEST_VAR_count", "2")
+	var d doc
+	// Environment variables are not used by default.
+	err := New().EvalBytes([]byte(f), &d, nil)
+	require.EqualError(t, err, `missing value for required variable "name"`)
+
+	require.NoError(t, New(WithEnvVars("TEST_VAR_")).EvalBytes([]byte(f), &d, nil))
+	require.Equal(t, doc{Name: "a8m", Count: 2}, d)
+
+	// Input values take precedence over environment variables.
+	require.NoError(t, New(WithEnvVars("TEST_VAR_")).EvalBytes([]byte(f), &d, map[string]cty.Value{"name": cty.StringVal("rotemtam")}))
+	require.Equal(t, doc{Name: "rotemtam", Count: 2}, d)
+}
+
+func TestWithValue(t *testing.T) {
+	type key struct{}
+	require.Nil(t, New().Value(key{}))
+	s := New(WithValue(key{}, true), WithValue("k", 1), WithValue("k", 2))
+	require.Equal(t, true, s.Value(key{}))
+	require.Equal(t, 2, s.Value("k"))
+}
+
+func TestWithoutAttrs(t *testing.T) {
+	type (
+		Child struct {
+			Name    string `spec:",name"`
+			Comment string `spec:"comment"`
+		}
+		doc struct {
+			Name     string   `spec:"name"`
+			Comment  string   `spec:"comment"`
+			Children []*Child `spec:"child"`
+		}
+	)
+	s := New(WithoutAttrs("comment"))
+	buf, err := s.MarshalSpec(&doc{Name: "a8m", Comment: "c1", Children: []*Child{{Name: "c", Comment: "c2"}}})
+	require.NoError(t, err)
+	require.Equal(t, `name = "a8m"
+child "c" {
+}
+`, string(buf))
+}
+
+func TestWithQuotedRefs(t *testing.T) {
+	type doc struct {
+		Refs []*Ref `spec:"refs"`
+	}
+	d := &doc{Refs: []*Ref{{V: "$table.users.$column.id"}, {V: "$column.my col"}}}
+	buf, err := New().MarshalSpec(d)
+	require.NoError(t, err)
+	require.Equal(t, `refs = [table.users.column.id, column["my col"]]
+`, string(buf))
+	buf, err = New(WithQuotedRefs()).MarshalSpec(d)
+	require.NoError(t, err)
+	require.Equal(t, `refs = [table["users"].column["id"], column["my col"]]
+`, string(buf))
+}
+
 func TestResource(t *testing.T) {
 	f := `endpoint "/hello" {
   description = "the hello handler"
@@ -0,0 +1,250 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schemahcl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// jsonResource is the JSON representation of a Resource. References, types and
+// raw expressions are represented as objects with a single "$ref", "$type" or
+// "$expr" key, respectively. For example:
+//
+//	{
+//	  "type": "table",
+//	  "name": "users",
+//	  "attrs": {"schema": {"$ref": "$schema.public"}},
+//	  "blocks": [
+//	    {"type": "column", "name": "id", "attrs": {"null": false, "type": {"$type": "int"}}}
+//	  ]
+//	}
+type jsonResource struct {
+	Type      string          `json:"type,omitempty"`
+	Qualifier string          `json:"qualifier,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Attrs     map[string]any  `json:"attrs,omitempty"`
+	Children  []*jsonResource `json:"blocks,omitempty"`
+}
+
+// MarshalSpecJSON is like MarshalSpec, but returns the JSON encoding of v
+// instead of an Atlas HCL document.
+func (s *State) MarshalSpecJSON(v any) ([]byte, error) {
+	r := &Resource{}
+	if err := r.Scan(v); err != nil {
+		return nil, fmt.Errorf("schemahcl: failed scanning %T to resource: %w", v, err)
+	}
+	j, err := s.toJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+// toJSON converts the given resource into its JSON representation.
+func (s *State) toJSON(r *Resource) (*jsonResource, error) {
+	j := &jsonResource{Type: r.Type, Qualifier: r.Qualifier, Name: r.Name}
+	for _, attr := range r.Attrs {
+		// Skip nil slices, as done when writing HCL documents.
+		if s.config.omit[attr.K] || attr.V.Type().IsListType() && attr.V.LengthInt() == 0 {
+			continue
+		}
+		v, err := s.jsonValue(attr.V)
+		if err != nil {
+			return nil, fmt.Errorf("schemahcl: attribute %q: %w", attr.K, err)
+		}
+		if j.Attrs == nil {
+			j.Attrs = make(map[string]any)
+		}
+		j.Attrs[attr.K] = v
+	}
+	for _, c := range r.Children {
+		cj, err := s.toJSON(c)
+		if err != nil {
+			return nil, err
+		}
+		j.Children = append(j.Children, cj)
+	}
+	return j, nil
+}
+
+// jsonValue returns the JSON representation of the given value.
+func (s *State) jsonValue(v cty.Value) (any, error) {
+	switch t := v.Type(); {
+	case v.IsNull():
+		return nil, nil
+	case t.IsCapsuleType():
+		switch x := v.EncapsulatedValue().(type) {
+		case *Ref:
+			return map[string]string{"$ref": x.V}, nil
+		case *RawExpr:
+			return map[string]string{"$expr": x.X}, nil
+		case *Type:
+			if x.IsRef {
+				return nil, fmt.Errorf("type reference %q is not supported in JSON documents", x.T)
+			}
+			e, err := s.typeExpr(x)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{"$type": e}, nil
+		default:
+			return nil, fmt.Errorf("unsupported capsule type: %v", t)
+		}
+	case t == cty.String:
+		return v.AsString(), nil
+	case t == cty.Number:
+		return json.Number(v.AsBigFloat().Text('f', -1)), nil
+	case t == cty.Bool:
+		return v.True(), nil
+	case t.IsListType(), t.IsTupleType(), t.IsSetType():
+		vs := make([]any, 0, v.LengthInt())
+		for _, e := range v.AsValueSlice() {
+			ev, err := s.jsonValue(e)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, ev)
+		}
+		return vs, nil
+	case t.IsMapType(), t.IsObjectType():
+		vs := make(map[string]any, v.LengthInt())
+		for k, e := range v.AsValueMap() {
+			ev, err := s.jsonValue(e)
+			if err != nil {
+				return nil, err
+			}
+			vs[k] = ev
+		}
+		return vs, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type: %v", t)
+	}
+}
+
+// EvalJSON evaluates the data byte-slice as the JSON representation of an Atlas
+// HCL document, as returned by MarshalSpecJSON, and stores the result in v.
+func (s *State) EvalJSON(data []byte, v any) error {
+	if s.config.err != nil {
+		return s.config.err
+	}
+	var j jsonResource
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&j); err != nil {
+		return fmt.Errorf("schemahcl: failed decoding JSON document: %w", err)
+	}
+	spec, err := s.fromJSON(s.config.newCtx(), &j)
+	if err != nil {
+		return err
+	}
+	if err := spec.As(v); err != nil {
+		return fmt.Errorf("schemahcl: failed reading spec as %T: %w", v, err)
+	}
+	return nil
+}
+
+// fromJSON converts the given JSON representation into a resource.
+func (s *State) fromJSON(ctx *hcl.EvalContext, j *jsonResource) (*Resource, error) {
+	r := &Resource{Type: j.Type, Qualifier: j.Qualifier, Name: j.Name}
+	// Attributes are sorted by their names, as JSON objects are unordered.
+	keys := make([]string, 0, len(j.Attrs))
+	for k := range j.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, err := s.ctyValue(ctx, j.Attrs[k])
+		if err != nil {
+			return nil, fmt.Errorf("schemahcl: attribute %q: %w", k, err)
+		}
+		r.Attrs = append(r.Attrs, &Attr{K: k, V: v})
+	}
+	for _, c := range j.Children {
+		cr, err := s.fromJSON(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		r.Children = append(r.Children, cr)
+	}
+	return r, nil
+}
+
+// ctyValue returns the cty.Value of the given JSON value.
+func (s *State) ctyValue(ctx *hcl.EvalContext, v any) (cty.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case string:
+		return cty.StringVal(v), nil
+	case bool:
+		return cty.BoolVal(v), nil
+	case json.Number:
+		return cty.ParseNumberVal(string(v))
+	case []any:
+		if len(v) == 0 {
+			return cty.ListValEmpty(cty.DynamicPseudoType), nil
+		}
+		vs := make([]cty.Value, 0, len(v))
+		for _, e := range v {
+			ev, err := s.ctyValue(ctx, e)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vs = append(vs, ev)
+		}
+		for _, ev := range vs[1:] {
+			if !ev.Type().Equals(vs[0].Type()) {
+				return cty.TupleVal(vs), nil
+			}
+		}
+		return cty.ListVal(vs), nil
+	case map[string]any:
+		if len(v) == 1 {
+			for k, x := range v {
+				x, ok := x.(string)
+				if !ok {
+					break
+				}
+				switch k {
+				case "$ref":
+					return cty.CapsuleVal(ctyRefType, &Ref{V: x}), nil
+				case "$expr":
+					return cty.CapsuleVal(ctyRawExpr, &RawExpr{X: x}), nil
+				case "$type":
+					expr, diags := hclsyntax.ParseExpression([]byte(x), "", hcl.InitialPos)
+					if diags.HasErrors() {
+						return cty.NilVal, diags
+					}
+					tv, diags := expr.Value(ctx)
+					if diags.HasErrors() {
+						return cty.NilVal, diags
+					}
+					if !tv.Type().Equals(ctyTypeSpec) {
+						return cty.NilVal, fmt.Errorf("invalid type %q", x)
+					}
+					return tv, nil
+				}
+			}
+		}
+		vs := make(map[string]cty.Value, len(v))
+		for k, e := range v {
+			ev, err := s.ctyValue(ctx, e)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vs[k] = ev
+		}
+		return cty.ObjectVal(vs), nil
+	default:
+		return cty.NilVal, fmt.Errorf("unsupported JSON value %T", v)
+	}
+}
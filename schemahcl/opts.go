@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -22,6 +23,19 @@ type (
 		newCtx   func() *hcl.EvalContext
 		pathVars map[string]map[string]cty.Value
 		datasrc  map[string]func(*hcl.EvalContext, *hclsyntax.Block) (cty.Value, error)
+		header   string
+		omit     map[string]bool
+		indent   string
+		newline  string
+		quote    bool
+		// envPrefix is the prefix of the environment variables that
+		// are used as input values. An empty string means disabled.
+		envPrefix string
+		// values holds driver-specific values, set by WithValue.
+		values map[any]any
+		// err holds the first error of the configured options,
+		// and it is returned by the evaluation functions.
+		err error
 	}
 
 	// Option configures a Config.
@@ -97,15 +111,126 @@ func WithDataSource(name string, h func(*hcl.EvalContext, *hclsyntax.Block) (cty
 	}
 }
 
+// WithHeader configures a header comment to be prepended to marshaled documents.
+// Lines that are not already commented are prefixed with "//". For example:
+//
+//	WithHeader("Generated by Atlas; do not edit")
+//
+//	// Generated by Atlas; do not edit
+//	table "users" {
+//		...
+//	}
+func WithHeader(header string) Option {
+	return func(c *Config) {
+		c.header = header
+	}
+}
+
+// WithEnvVars configures the evaluation to resolve input variables that are missing
+// from the input values, from the environment variables with the given prefix. For
+// security reasons, environment variables are never used if this option is not set.
+// For example, the following option resolves "var.tenant" from ATLAS_VAR_tenant:
+//
+//	WithEnvVars("ATLAS_VAR_")
+func WithEnvVars(prefix string) Option {
+	return func(c *Config) {
+		c.envPrefix = prefix
+	}
+}
+
+// WithValue configures a driver-specific value for the given key, that can be read
+// using State.Value. It allows drivers to define their own options, that can be
+// combined with the options of this package. For example:
+//
+//	type strictKey struct{}
+//
+//	WithValue(strictKey{}, true)
+func WithValue(key, value any) Option {
+	return func(c *Config) {
+		if c.values == nil {
+			c.values = make(map[any]any)
+		}
+		c.values[key] = value
+	}
+}
+
+// Value returns the value configured for the given key using WithValue, or nil.
+func (s *State) Value(key any) any {
+	return s.config.values[key]
+}
+
+// WithIndent configures the indentation used for nested blocks in marshaled
+// documents, instead of the default two spaces. For example:
+//
+//	WithIndent("\t")		// Tabs.
+//	WithIndent("    ")	// 4 spaces.
+func WithIndent(indent string) Option {
+	return func(c *Config) {
+		c.indent = indent
+	}
+}
+
+// WithNewline configures the line ending used in marshaled
+// documents, instead of the default LF. For example:
+//
+//	WithNewline("\r\n")	// CRLF.
+func WithNewline(newline string) Option {
+	return func(c *Config) {
+		c.newline = newline
+	}
+}
+
+// WithQuotedRefs configures the marshaler to quote all names in references,
+// even if they are valid identifiers. For example:
+//
+//	table "users" {
+//		schema = schema["public"]
+//		...
+//		primary_key {
+//			columns = [column["id"]]
+//		}
+//	}
+func WithQuotedRefs() Option {
+	return func(c *Config) {
+		c.quote = true
+	}
+}
+
+// WithoutAttrs configures the marshaler to omit attributes with the given
+// names from the marshaled document, regardless of the block they belong to.
+func WithoutAttrs(names ...string) Option {
+	return func(c *Config) {
+		if c.omit == nil {
+			c.omit = make(map[string]bool, len(names))
+		}
+		for _, n := range names {
+			c.omit[n] = true
+		}
+	}
+}
+
 // WithTypes configures the list of given types as identifiers in the unmarshaling context.
+// Invalid types, such as namespaced types (e.g. "app.uuid") with required arguments, are
+// reported as errors by the evaluation functions of the State.
 func WithTypes(typeSpecs []*TypeSpec) Option {
 	newCtx := func() *hcl.EvalContext {
 		ctx := stdTypes(&hcl.EvalContext{
 			Functions: stdFuncs(),
 			Variables: make(map[string]cty.Value),
 		})
+		namespaces := make(map[string]map[string]cty.Value)
 		for _, ts := range typeSpecs {
 			typeSpec := ts
+			// Namespaced types (e.g. "app.uuid") are registered as attributes of their
+			// namespace object. HCL does not support namespaced functions, and therefore,
+			// these types cannot have arguments.
+			if ns, name, ok := strings.Cut(typeSpec.Name, "."); ok {
+				if namespaces[ns] == nil {
+					namespaces[ns] = make(map[string]cty.Value)
+				}
+				namespaces[ns][name] = cty.CapsuleVal(ctyTypeSpec, &Type{T: typeSpec.T})
+				continue
+			}
 			// If no required args exist, register the type as a variable in the HCL context.
 			if len(typeFuncReqArgs(typeSpec)) == 0 {
 				typ := &Type{T: typeSpec.T}
@@ -116,12 +241,18 @@ func WithTypes(typeSpecs []*TypeSpec) Option {
 				ctx.Functions[typeSpec.Name] = typeFuncSpec(typeSpec)
 			}
 		}
+		for ns, types := range namespaces {
+			ctx.Variables[ns] = cty.ObjectVal(types)
+		}
 		ctx.Functions["sql"] = rawExprImpl()
 		return ctx
 	}
 	return func(c *Config) {
 		c.newCtx = newCtx
 		c.types = append(c.types, typeSpecs...)
+		if c.err == nil {
+			c.err = validSpecs(typeSpecs)
+		}
 	}
 }
 
@@ -206,8 +337,7 @@ func typeFuncSpecImpl(_ *function.Spec, typeSpec *TypeSpec) function.ImplFunc {
 func typeFuncArgs(spec *TypeSpec) []*TypeAttr {
 	var args []*TypeAttr
 	for _, attr := range spec.Attributes {
-		// TODO(rotemtam): this should be defined on the TypeSpec.
-		if attr.Name == "unsigned" {
+		if attr.isModifier() {
 			continue
 		}
 		args = append(args, attr)
@@ -81,6 +81,11 @@ type (
 		Name     string
 		Kind     reflect.Kind
 		Required bool
+		// Modifier indicates the attribute is a boolean modifier that is
+		// defined after the type and its arguments, e.g. `int unsigned`,
+		// instead of being an argument of the type, e.g. `varchar(255)`.
+		// For compatibility, the `unsigned` attribute is always a modifier.
+		Modifier bool
 	}
 
 	// Type represents the type of the field in a schema.
@@ -91,6 +96,11 @@ type (
 	}
 )
 
+// isModifier reports if the attribute is a modifier of the type.
+func (a *TypeAttr) isModifier() bool {
+	return a.Modifier || a.Name == "unsigned"
+}
+
 // IsRef indicates if the attribute is a reference type.
 func (a *Attr) IsRef() bool {
 	if !a.V.Type().IsCapsuleType() {
@@ -14,6 +14,8 @@ import (
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/go-openapi/inflect"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // PrintType returns the string representation of a column type which can be parsed
@@ -31,26 +33,29 @@ func (r *TypeRegistry) PrintType(typ *Type) (string, error) {
 		mid, suffix string
 	)
 	for _, arg := range typ.Attrs {
-		// TODO(rotemtam): make this part of the TypeSpec
-		if arg.K == "unsigned" {
-			b, err := arg.Bool()
-			if err != nil {
-				return "", err
-			}
-			if b {
-				suffix += " unsigned"
-			}
-			continue
-		}
 		attr, ok := spec.Attr(arg.K)
 		if !ok {
 			return "", fmt.Errorf("specutil: attribute %q not found in typespec %q", arg.K, typ.T)
 		}
+		if attr.isModifier() {
+			if _, err := arg.Bool(); err != nil {
+				return "", err
+			}
+			continue
+		}
 		args = append(args, valueArgs(attr, arg.V)...)
 	}
 	if len(args) > 0 {
 		mid = "(" + strings.Join(args, ",") + ")"
 	}
+	// Modifiers are printed in their order in the spec.
+	for _, m := range typeNonFuncArgs(spec) {
+		for _, arg := range typ.Attrs {
+			if b, _ := arg.Bool(); arg.K == m.Name && b {
+				suffix += " " + m.Name
+			}
+		}
+	}
 	return typ.T + mid + suffix, nil
 }
 
@@ -106,12 +111,19 @@ func (r *TypeRegistry) Register(specs ...*TypeSpec) error {
 		if _, exists := r.findName(s.Name); exists {
 			return fmt.Errorf("specutil: type with name of %q already registered", s.T)
 		}
+		if err := validNamespaces(append(r.r[:len(r.r):len(r.r)], s)); err != nil {
+			return fmt.Errorf("specutil: invalid typespec %q: %w", s.Name, err)
+		}
 		r.r = append(r.r, s)
 	}
 	return nil
 }
 
 func validSpec(typeSpec *TypeSpec) error {
+	// HCL does not support namespaced functions.
+	if strings.Contains(typeSpec.Name, ".") && len(typeFuncReqArgs(typeSpec)) > 0 {
+		return errors.New("namespaced type cannot have required attributes")
+	}
 	var seenOptional bool
 	for i, attr := range typeSpec.Attributes {
 		if attr.Kind == reflect.Slice && i < len(typeSpec.Attributes)-1 {
@@ -125,6 +137,57 @@ func validSpec(typeSpec *TypeSpec) error {
 	return nil
 }
 
+// validSpecs validates the given type specs, including their namespaces.
+func validSpecs(specs []*TypeSpec) error {
+	for _, s := range specs {
+		if err := validSpec(s); err != nil {
+			return fmt.Errorf("schemahcl: invalid typespec %q: %w", s.Name, err)
+		}
+	}
+	if err := validNamespaces(specs); err != nil {
+		return fmt.Errorf("schemahcl: %w", err)
+	}
+	return nil
+}
+
+// reservedNamespaces holds the root identifiers of references in documents
+// (e.g. var.name or table.users), that cannot be used as type namespaces.
+var reservedNamespaces = map[string]bool{
+	varRef:    true,
+	localRef:  true,
+	eachRef:   true,
+	dataBlock: true,
+	"schema":  true,
+	"table":   true,
+	"column":  true,
+	"enum":    true,
+}
+
+// validNamespaces checks that the namespaces of namespaced types (e.g. "app.uuid") are not
+// reserved identifiers of the document, and do not collide with the builtin types or the
+// given types that are registered as variables.
+func validNamespaces(specs []*TypeSpec) error {
+	vars := stdTypes(&hcl.EvalContext{}).Variables
+	for _, s := range specs {
+		if !strings.Contains(s.Name, ".") && len(typeFuncReqArgs(s)) == 0 {
+			vars[s.Name] = cty.NilVal
+		}
+	}
+	for _, s := range specs {
+		ns, _, ok := strings.Cut(s.Name, ".")
+		if !ok {
+			continue
+		}
+		if reservedNamespaces[ns] {
+			return fmt.Errorf("namespace of type %q is a reserved identifier", s.Name)
+		}
+		if _, ok := vars[ns]; ok {
+			return fmt.Errorf("namespace of type %q collides with type %q", s.Name, ns)
+		}
+	}
+	return nil
+}
+
 // TypeRegistryOption configures a TypeRegistry.
 type TypeRegistryOption func(*TypeRegistry) error
 
@@ -348,8 +411,7 @@ func SizeTypeAttr(required bool) *TypeAttr {
 func typeNonFuncArgs(spec *TypeSpec) []*TypeAttr {
 	var args []*TypeAttr
 	for _, attr := range spec.Attributes {
-		// TODO(rotemtam): this should be defined on the TypeSpec.
-		if attr.Name == "unsigned" {
+		if attr.isModifier() {
 			args = append(args, attr)
 		}
 	}
@@ -5,6 +5,7 @@
 package schemahcl
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -36,6 +37,29 @@ func TestTypePrint(t *testing.T) {
 			typ:      &Type{T: "int", Attrs: []*Attr{BoolAttr("unsigned", true)}},
 			expected: "int unsigned",
 		},
+		{
+			spec: &TypeSpec{
+				Name: "int",
+				T:    "int",
+				Attributes: []*TypeAttr{
+					unsignedTypeAttr(),
+					{Name: "zerofill", Kind: reflect.Bool, Modifier: true},
+					{Name: "size", Kind: reflect.Int},
+				},
+			},
+			typ:      &Type{T: "int", Attrs: []*Attr{BoolAttr("zerofill", true), IntAttr("size", 10), BoolAttr("unsigned", true)}},
+			expected: "int(10) unsigned zerofill",
+		},
+		{
+			// Unsigned attributes that are not declared as modifiers.
+			spec: &TypeSpec{
+				Name:       "int",
+				T:          "int",
+				Attributes: []*TypeAttr{{Name: "unsigned", Kind: reflect.Bool}},
+			},
+			typ:      &Type{T: "int", Attrs: []*Attr{BoolAttr("unsigned", true)}},
+			expected: "int unsigned",
+		},
 		{
 			spec: &TypeSpec{
 				Name:       "float",
@@ -45,6 +69,19 @@ func TestTypePrint(t *testing.T) {
 			typ:      &Type{T: "float", Attrs: []*Attr{BoolAttr("unsigned", true)}},
 			expected: "float unsigned",
 		},
+		{
+			// Modifiers are declared by the type spec.
+			spec: &TypeSpec{
+				Name: "char",
+				T:    "char",
+				Attributes: []*TypeAttr{
+					{Name: "size", Kind: reflect.Int},
+					{Name: "binary", Kind: reflect.Bool, Modifier: true},
+				},
+			},
+			typ:      &Type{T: "char", Attrs: []*Attr{BoolAttr("binary", true), IntAttr("size", 10)}},
+			expected: "char(10) binary",
+		},
 		{
 			spec: &TypeSpec{
 				T:    "varchar",
@@ -75,6 +112,17 @@ func TestRegistry(t *testing.T) {
 	require.NoError(t, err)
 	err = r.Register(text)
 	require.EqualError(t, err, `specutil: type with T of "text" already registered`)
+	err = r.Register(&TypeSpec{Name: "app.varchar", T: "app_varchar", Attributes: []*TypeAttr{{Name: "size", Kind: reflect.Int, Required: true}}})
+	require.EqualError(t, err, `specutil: invalid typespec "app.varchar": namespaced type cannot have required attributes`)
+	err = r.Register(&TypeSpec{Name: "text.uuid", T: "text_uuid"})
+	require.EqualError(t, err, `specutil: invalid typespec "text.uuid": namespace of type "text.uuid" collides with type "text"`)
+	err = r.Register(&TypeSpec{Name: "string.uuid", T: "string_uuid"})
+	require.EqualError(t, err, `specutil: invalid typespec "string.uuid": namespace of type "string.uuid" collides with type "string"`)
+	for _, ns := range []string{"var", "local", "schema", "table", "column"} {
+		err = r.Register(&TypeSpec{Name: ns + ".uuid", T: ns + "_uuid"})
+		require.EqualError(t, err, fmt.Sprintf(`specutil: invalid typespec "%s.uuid": namespace of type "%s.uuid" is a reserved identifier`, ns, ns))
+	}
+	require.NoError(t, r.Register(&TypeSpec{Name: "app.uuid", T: "app_uuid"}))
 	spec, ok := r.findName("text")
 	require.True(t, ok)
 	require.EqualValues(t, spec, text)
@@ -231,7 +279,8 @@ func TestRegistryConvert(t *testing.T) {
 
 func unsignedTypeAttr() *TypeAttr {
 	return &TypeAttr{
-		Name: "unsigned",
-		Kind: reflect.Bool,
+		Name:     "unsigned",
+		Kind:     reflect.Bool,
+		Modifier: true,
 	}
 }
@@ -5,6 +5,7 @@
 package specutil
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -37,39 +38,108 @@ type (
 
 // Scan populates the Realm from the schemas and table specs.
 func Scan(r *schema.Realm, schemas []*sqlspec.Schema, tables []*sqlspec.Table, convertTable ConvertTableFunc) error {
+	return ScanContext(context.Background(), r, schemas, tables, convertTable)
+}
+
+// ScanContext is like Scan, but checks the given context for cancellation
+// between tables, during both the conversion and the linking phases.
+func ScanContext(ctx context.Context, r *schema.Realm, schemas []*sqlspec.Schema, tables []*sqlspec.Table, convertTable ConvertTableFunc) error {
+	return scan(ctx, r, schemas, tables, convertTable, false)
+}
+
+// ScanAll is like ScanContext, but instead of returning on the first conversion
+// error, it converts all tables in the document and returns the accumulated errors
+// as Errors. Tables that failed to convert are not added to the Realm. Errors lists
+// returned by convertTable are expected to describe their table, and are appended
+// as-is. Other errors are prefixed with the table name.
+func ScanAll(ctx context.Context, r *schema.Realm, schemas []*sqlspec.Schema, tables []*sqlspec.Table, convertTable ConvertTableFunc) error {
+	return scan(ctx, r, schemas, tables, convertTable, true)
+}
+
+// scan populates the Realm from the schemas and table specs. If all is true, the
+// conversion errors are accumulated instead of returning on the first one.
+func scan(ctx context.Context, r *schema.Realm, schemas []*sqlspec.Schema, tables []*sqlspec.Table, convertTable ConvertTableFunc, all bool) error {
+	var errs Errors
+	// add records the error of the given table,
+	// and reports if the scan should continue.
+	add := func(table string, err error) bool {
+		if !all {
+			return false
+		}
+		var inner Errors
+		if errors.As(err, &inner) {
+			errs = append(errs, inner...)
+		} else {
+			errs = append(errs, fmt.Errorf("table %q: %w", table, err))
+		}
+		return true
+	}
 	// Build the schemas.
 	for _, schemaSpec := range schemas {
 		sch := &schema.Schema{Name: schemaSpec.Name, Realm: r}
 		for _, tableSpec := range tables {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			name, err := SchemaName(tableSpec.Schema)
 			if err != nil {
-				return fmt.Errorf("specutil: cannot extract schema name for table %q: %w", tableSpec.Name, err)
+				err = fmt.Errorf("specutil: cannot extract schema name for table %q: %w", tableSpec.Name, err)
+				if !add(tableSpec.Name, err) {
+					return err
+				}
+				continue
 			}
-			if name == schemaSpec.Name {
-				tbl, err := convertTable(tableSpec, sch)
-				if err != nil {
+			if name != schemaSpec.Name {
+				continue
+			}
+			tbl, err := convertTable(tableSpec, sch)
+			if err != nil {
+				if !add(tableSpec.Name, err) {
 					return err
 				}
-				sch.Tables = append(sch.Tables, tbl)
+				continue
 			}
+			sch.Tables = append(sch.Tables, tbl)
 		}
 		r.Schemas = append(r.Schemas, sch)
 	}
 	// Link the foreign keys.
 	for _, sch := range r.Schemas {
 		for _, tbl := range sch.Tables {
-			tableSpec, err := findTableSpec(tables, sch.Name, tbl.Name)
-			if err != nil {
+			if err := ctx.Err(); err != nil {
 				return err
 			}
-			if err := linkForeignKeys(tbl, sch, tableSpec); err != nil {
+			tableSpec, err := findTableSpec(tables, sch.Name, tbl.Name)
+			if err == nil {
+				err = linkForeignKeys(tbl, sch, tableSpec)
+			}
+			if err != nil && !add(tbl.Name, err) {
 				return err
 			}
 		}
 	}
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
+// Errors is a list of conversion errors that were accumulated in a single
+// pass over a document. It implements the error interface.
+type Errors []error
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Errors returns the accumulated errors.
+func (e Errors) Errors() []error { return e }
+
 // findTableSpec searches tableSpecs for a spec of a table named tableName in a schema named schemaName.
 func findTableSpec(tableSpecs []*sqlspec.Table, schemaName, tableName string) (*sqlspec.Table, error) {
 	for _, tbl := range tableSpecs {
@@ -89,42 +159,83 @@ func findTableSpec(tableSpecs []*sqlspec.Table, schemaName, tableName string) (*
 // at this point. Instead, the linking is done by the Schema function.
 func Table(spec *sqlspec.Table, parent *schema.Schema, convertColumn ConvertColumnFunc,
 	convertPK ConvertPrimaryKeyFunc, convertIndex ConvertIndexFunc, convertCheck ConvertCheckFunc) (*schema.Table, error) {
-	tbl := &schema.Table{
-		Name:   spec.Name,
-		Schema: parent,
+	tbl, errs := table(spec, parent, convertColumn, convertPK, convertIndex, convertCheck, false)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return tbl, nil
+}
+
+// TableAll is like Table, but instead of returning on the first conversion error, it converts
+// all columns, indexes and checks of the table, and returns the accumulated errors, prefixed with
+// their element names. Elements that failed to convert are not added to the returned table.
+func TableAll(spec *sqlspec.Table, parent *schema.Schema, convertColumn ConvertColumnFunc,
+	convertPK ConvertPrimaryKeyFunc, convertIndex ConvertIndexFunc, convertCheck ConvertCheckFunc) (*schema.Table, Errors) {
+	return table(spec, parent, convertColumn, convertPK, convertIndex, convertCheck, true)
+}
+
+// table implements Table and TableAll. If all is false, the returned table
+// is nil in case of an error, and the errors hold the first error as-is.
+func table(spec *sqlspec.Table, parent *schema.Schema, convertColumn ConvertColumnFunc,
+	convertPK ConvertPrimaryKeyFunc, convertIndex ConvertIndexFunc, convertCheck ConvertCheckFunc, all bool) (*schema.Table, Errors) {
+	var (
+		errs Errors
+		tbl  = &schema.Table{
+			Name:   spec.Name,
+			Schema: parent,
+		}
+	)
+	// add records the error of the given element,
+	// and reports if the conversion should continue.
+	add := func(elem string, err error) bool {
+		if !all {
+			errs = append(errs, err)
+			return false
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", elem, err))
+		return true
 	}
 	for _, csp := range spec.Columns {
 		col, err := convertColumn(csp, tbl)
 		if err != nil {
-			return nil, err
+			if !add(fmt.Sprintf("column %q", csp.Name), err) {
+				return nil, errs
+			}
+			continue
 		}
 		tbl.Columns = append(tbl.Columns, col)
 	}
 	if spec.PrimaryKey != nil {
 		pk, err := convertPK(spec.PrimaryKey, tbl)
-		if err != nil {
-			return nil, err
+		if err != nil && !add("primary key", err) {
+			return nil, errs
 		}
 		tbl.PrimaryKey = pk
 	}
 	for _, idx := range spec.Indexes {
 		i, err := convertIndex(idx, tbl)
 		if err != nil {
-			return nil, err
+			if !add(fmt.Sprintf("index %q", idx.Name), err) {
+				return nil, errs
+			}
+			continue
 		}
 		tbl.Indexes = append(tbl.Indexes, i)
 	}
 	for _, c := range spec.Checks {
-		c, err := convertCheck(c)
+		ck, err := convertCheck(c)
 		if err != nil {
-			return nil, err
+			if !add(fmt.Sprintf("check %q", c.Name), err) {
+				return nil, errs
+			}
+			continue
 		}
-		tbl.AddChecks(c)
+		tbl.AddChecks(ck)
 	}
-	if err := convertCommentFromSpec(spec, &tbl.Attrs); err != nil {
-		return nil, err
+	if err := convertCommentFromSpec(spec, &tbl.Attrs); err != nil && !add("comment", err) {
+		return nil, errs
 	}
-	return tbl, nil
+	return tbl, errs
 }
 
 // Column converts a sqlspec.Column into a schema.Column.
@@ -231,6 +342,9 @@ func Check(spec *sqlspec.Check) (*schema.Check, error) {
 
 // PrimaryKey converts a sqlspec.PrimaryKey to a schema.Index.
 func PrimaryKey(spec *sqlspec.PrimaryKey, parent *schema.Table) (*schema.Index, error) {
+	if len(spec.Parts) > 0 {
+		return primaryKeyParts(spec, parent)
+	}
 	parts := make([]*schema.IndexPart, 0, len(spec.Columns))
 	for seqno, c := range spec.Columns {
 		c, err := ColumnByRef(parent, c)
@@ -248,6 +362,29 @@ func PrimaryKey(spec *sqlspec.PrimaryKey, parent *schema.Table) (*schema.Index,
 	}, nil
 }
 
+// primaryKeyParts converts a sqlspec.PrimaryKey that is defined
+// using "on" blocks, instead of "columns", to a schema.Index.
+func primaryKeyParts(spec *sqlspec.PrimaryKey, parent *schema.Table) (*schema.Index, error) {
+	if len(spec.Columns) > 0 {
+		return nil, fmt.Errorf(`multiple definitions for the primary key of table %q, use "columns" or "on"`, parent.Name)
+	}
+	parts := make([]*schema.IndexPart, 0, len(spec.Parts))
+	for i, p := range spec.Parts {
+		if p.Column == nil || p.Expr != "" {
+			return nil, fmt.Errorf(`"column" is required for the primary key of table %q at position %d`, parent.Name, i)
+		}
+		c, err := ColumnByRef(parent, p.Column)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, &schema.IndexPart{SeqNo: i, C: c, Desc: p.Desc})
+	}
+	return &schema.Index{
+		Table: parent,
+		Parts: parts,
+	}, nil
+}
+
 // linkForeignKeys creates the foreign keys defined in the Table's spec by creating references
 // to column in the provided Schema. It is assumed that all tables referenced FK definitions in the spec
 // are reachable from the provided schema or its connected realm.
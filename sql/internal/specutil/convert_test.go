@@ -5,6 +5,7 @@
 package specutil
 
 import (
+	"context"
 	"testing"
 
 	"ariga.io/atlas/schemahcl"
@@ -89,3 +90,26 @@ func TestFromForeignKey(t *testing.T) {
 		},
 	}, key)
 }
+
+func TestScanContext_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var (
+		r       schema.Realm
+		visited []string
+		schemas = []*sqlspec.Schema{{Name: "s"}}
+		tables  = []*sqlspec.Table{
+			{Name: "t1", Schema: SchemaRef("s")},
+			{Name: "t2", Schema: SchemaRef("s")},
+			{Name: "t3", Schema: SchemaRef("s")},
+		}
+	)
+	err := ScanContext(ctx, &r, schemas, tables, func(spec *sqlspec.Table, s *schema.Schema) (*schema.Table, error) {
+		visited = append(visited, spec.Name)
+		// Cancel the scan after the first table.
+		cancel()
+		return &schema.Table{Name: spec.Name, Schema: s}, nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, []string{"t1"}, visited)
+}
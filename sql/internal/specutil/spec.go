@@ -6,6 +6,7 @@ package specutil
 
 import (
 	"fmt"
+	"io"
 
 	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/schema"
@@ -28,6 +29,52 @@ type doc struct {
 // Marshal marshals v into an Atlas DDL document using a schemahcl.Marshaler. Marshal uses the given
 // schemaSpec function to convert a *schema.Schema into *sqlspec.Schema and []*sqlspec.Table.
 func Marshal(v any, marshaler schemahcl.Marshaler, schemaSpec func(schem *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error)) ([]byte, error) {
+	d, err := toDoc(v, schemaSpec)
+	if err != nil {
+		return nil, err
+	}
+	return marshaler.MarshalSpec(d)
+}
+
+// MarshalTo is like Marshal, but writes the document to w. The document is converted
+// as a whole, but its tables and schemas are encoded and written one by one.
+func MarshalTo(w io.Writer, v any, marshaler schemahcl.Marshaler, schemaSpec func(schem *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error)) error {
+	d, err := toDoc(v, schemaSpec)
+	if err != nil {
+		return err
+	}
+	chunks := make([]*doc, 0, len(d.Tables)+len(d.Schemas))
+	for _, t := range d.Tables {
+		chunks = append(chunks, &doc{Tables: []*sqlspec.Table{t}})
+	}
+	for _, s := range d.Schemas {
+		chunks = append(chunks, &doc{Schemas: []*sqlspec.Schema{s}})
+	}
+	// Marshalers that support streaming, such as *schemahcl.State,
+	// write their header (if configured) only once for the document.
+	if m, ok := marshaler.(interface {
+		MarshalSpecTo(io.Writer, ...any) error
+	}); ok {
+		vs := make([]any, len(chunks))
+		for i := range chunks {
+			vs[i] = chunks[i]
+		}
+		return m.MarshalSpecTo(w, vs...)
+	}
+	for _, c := range chunks {
+		b, err := marshaler.MarshalSpec(c)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toDoc converts v into a document using the given schemaSpec function.
+func toDoc(v any, schemaSpec func(schem *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error)) (*doc, error) {
 	d := &doc{}
 	switch s := v.(type) {
 	case *schema.Schema:
@@ -49,13 +96,16 @@ func Marshal(v any, marshaler schemahcl.Marshaler, schemaSpec func(schem *schema
 		if err := QualifyDuplicates(d.Tables); err != nil {
 			return nil, err
 		}
+		if err := QualifyCrossReferences(d.Tables, s); err != nil {
+			return nil, err
+		}
 		if err := QualifyReferences(d.Tables, s); err != nil {
 			return nil, err
 		}
 	default:
 		return nil, fmt.Errorf("specutil: failed marshaling spec. %T is not supported", v)
 	}
-	return marshaler.MarshalSpec(d)
+	return d, nil
 }
 
 // QualifyDuplicates sets the Qualified field equal to the schema name in any tables
@@ -80,6 +130,36 @@ func QualifyDuplicates(tableSpecs []*sqlspec.Table) error {
 	return nil
 }
 
+// QualifyCrossReferences sets the Qualified field equal to the schema name in any tables
+// that are referenced by foreign keys defined in tables of other schemas. This ensures
+// that cross-schema references are emitted with their schema qualifier.
+func QualifyCrossReferences(tableSpecs []*sqlspec.Table, realm *schema.Realm) error {
+	type tref struct{ s, t string }
+	byRef := make(map[tref]*sqlspec.Table, len(tableSpecs))
+	for _, t := range tableSpecs {
+		sname, err := SchemaName(t.Schema)
+		if err != nil {
+			return err
+		}
+		byRef[tref{s: sname, t: t.Name}] = t
+	}
+	for _, s := range realm.Schemas {
+		for _, t := range s.Tables {
+			for _, fk := range t.ForeignKeys {
+				if fk.RefTable == nil || fk.RefTable.Schema == nil || fk.RefTable.Schema.Name == s.Name {
+					continue
+				}
+				r, ok := byRef[tref{s: fk.RefTable.Schema.Name, t: fk.RefTable.Name}]
+				if !ok {
+					return fmt.Errorf("table %q.%q was not found in specs", fk.RefTable.Schema.Name, fk.RefTable.Name)
+				}
+				r.Qualifier = fk.RefTable.Schema.Name
+			}
+		}
+	}
+	return nil
+}
+
 // QualifyReferences qualifies any reference with qualifier.
 func QualifyReferences(tableSpecs []*sqlspec.Table, realm *schema.Realm) error {
 	type cref struct{ s, t string }
@@ -377,6 +377,9 @@ const (
 	// atlas:delimiter directive.
 	directiveDelimiter = "delimiter"
 	directivePrefixSQL = "-- "
+	// atlas:batch directive.
+	directiveBatch = "batch"
+	batchBlankLine = "blankline"
 )
 
 var reDirective = regexp.MustCompile(`^([ -~]*)atlas:(\w+)(?: +([ -~]*))*`)
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -41,52 +42,307 @@ func (s *Stmt) Directive(name string) (ds []string) {
 	return
 }
 
+// StmtsOption allows configuring the lexer used by Stmts.
+type StmtsOption func(*lex)
+
+// WithHashComments configures whether the hash character (#) starts a single-line
+// comment, as in MySQL. It is disabled by default, as other dialects use the '#'
+// character in statements (e.g. as an operator in PostgreSQL).
+func WithHashComments(enable bool) StmtsOption {
+	return func(l *lex) {
+		l.hash = enable
+	}
+}
+
+// WithRoutineBodies configures the lexer to scan the bodies of stored routines, triggers
+// and events (e.g. "CREATE PROCEDURE ... BEGIN ... END") as part of a single statement,
+// by tracking the nesting of their BEGIN ... END blocks. Hence, semicolons in routine
+// bodies do not end the statement, and the DELIMITER command is not needed for them.
+func WithRoutineBodies(enable bool) StmtsOption {
+	return func(l *lex) {
+		l.routines = enable
+	}
+}
+
+// WithExecutableComments configures the lexer to keep MySQL executable comments that
+// start a statement (e.g. "/*!50003 CREATE TRIGGER ... */" in mysqldump files) as the
+// statement text, instead of skipping them as regular comments.
+func WithExecutableComments(enable bool) StmtsOption {
+	return func(l *lex) {
+		l.exec = enable
+	}
+}
+
 // Stmts provides a generic implementation for extracting SQL statements from the given file contents.
-func Stmts(input string) ([]*Stmt, error) {
+func Stmts(input string, opts ...StmtsOption) ([]*Stmt, error) {
 	var stmts []*Stmt
-	l, err := newLex(input)
-	if err != nil {
+	if err := scanStmts(input, func(s *Stmt) error {
+		stmts = append(stmts, s)
+		return nil
+	}, opts...); err != nil {
 		return nil, err
 	}
+	return stmts, nil
+}
+
+// StmtsFunc is like Stmts, but instead of collecting the statements, it calls fn
+// with the text of each statement as it is scanned. Scanning stops on the first
+// error returned by fn, and the error is returned to the caller.
+func StmtsFunc(input string, fn func(stmt string) error, opts ...StmtsOption) error {
+	return scanStmts(input, func(s *Stmt) error {
+		return fn(s.Text)
+	}, opts...)
+}
+
+// CommentedStmts is like Stmts, but returns the text of each statement prefixed with
+// its immediately preceding comments block, one comment per line. Hence, joining the
+// returned statements with a newline keeps the documentation of the file. Comments
+// that are separated from the statement by a blank line are not attached to it.
+func CommentedStmts(input string, opts ...StmtsOption) ([]string, error) {
+	var stmts []string
+	if err := scanStmts(input, func(s *Stmt) error {
+		var b strings.Builder
+		for _, c := range s.Comments {
+			b.WriteString(strings.TrimSpace(c))
+			b.WriteByte('\n')
+		}
+		b.WriteString(s.Text)
+		stmts = append(stmts, b.String())
+		return nil
+	}, opts...); err != nil {
+		return nil, err
+	}
+	return stmts, nil
+}
+
+// StmtError describes a statement that could not be scanned.
+type StmtError struct {
+	Pos  int    // statement position
+	Text string // statement text, up to the next delimiter
+	Err  error  // scanning error
+}
+
+// Error implements the error interface.
+func (e *StmtError) Error() string {
+	return fmt.Sprintf("statement at position %d: %v", e.Pos, e.Err)
+}
+
+// Unwrap returns the underlying scanning error.
+func (e *StmtError) Unwrap() error {
+	return e.Err
+}
+
+// StmtsRecover is like Stmts, but instead of failing on the first malformed statement
+// (e.g. one with an unclosed quote), it skips to the next delimiter and continues
+// scanning. It returns the successfully scanned statements, and the errors of the
+// skipped statements. Hence, tools can report all problems in a file at once.
+func StmtsRecover(input string, opts ...StmtsOption) ([]*Stmt, []*StmtError) {
+	l, err := newLex(input, opts...)
+	if err != nil {
+		return nil, []*StmtError{{Err: err}}
+	}
+	var (
+		stmts []*Stmt
+		errs  []*StmtError
+	)
+	for {
+		s, err := l.stmt()
+		switch {
+		case err == io.EOF:
+			return stmts, errs
+		case err != nil:
+			errs = append(errs, l.recover(err))
+		default:
+			stmts = append(stmts, s)
+		}
+	}
+}
+
+// scanStmts scans the statements in the given input and calls fn for each of them.
+func scanStmts(input string, fn func(*Stmt) error, opts ...StmtsOption) error {
+	l, err := newLex(input, opts...)
+	if err != nil {
+		return err
+	}
 	for {
 		s, err := l.stmt()
 		if err == io.EOF {
-			return stmts, nil
+			return nil
 		}
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+}
+
+// TxStmts is like Stmts, but groups the statements of the given file contents into
+// transaction batches. Statements enclosed between BEGIN (or START TRANSACTION) and
+// COMMIT (or ROLLBACK) are grouped into a single batch, including the transaction
+// statements themselves, and any other statement is returned in its own batch.
+func TxStmts(input string, opts ...StmtsOption) ([][]string, error) {
+	var (
+		tx      []string
+		batches [][]string
+	)
+	err := scanStmts(input, func(s *Stmt) error {
+		switch kind := txKind(s.Text); {
+		case kind == txBegin && tx != nil:
+			return fmt.Errorf("nested transaction at position %d", s.Pos)
+		case kind == txBegin:
+			tx = []string{s.Text}
+		case tx != nil && kind == txEnd:
+			batches, tx = append(batches, append(tx, s.Text)), nil
+		case tx != nil:
+			tx = append(tx, s.Text)
+		default:
+			batches = append(batches, []string{s.Text})
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if tx != nil {
+		return nil, errors.New("unterminated transaction: missing COMMIT or ROLLBACK")
+	}
+	return batches, nil
+}
+
+// Delimiter returns the delimiter configured by the "atlas:delimiter" directive at
+// the beginning of the given file contents, and reports if one was set, even if it
+// is the default one. Only the directives are read, and the statements of the file
+// are not scanned. An error is returned in case the directives are malformed.
+func Delimiter(input string) (string, bool, error) {
+	l, err := newLex(input)
+	if err != nil {
+		return "", false, err
+	}
+	if !l.directive {
+		return "", false, nil
+	}
+	return l.delim, true, nil
+}
+
+// Transaction statement kinds.
+const (
+	txBegin = "begin"
+	txEnd   = "end"
+)
+
+// txKind reports if the statement starts (txBegin) or
+// ends (txEnd) a transaction, or an empty string otherwise.
+func txKind(stmt string) string {
+	fields := strings.FieldsFunc(strings.ToUpper(stmt), func(r rune) bool {
+		return unicode.IsSpace(r) || r == ';'
+	})
+	switch k := StmtKind(stmt); {
+	case k == "BEGIN" && (len(fields) == 1 || fields[1] == "WORK" || fields[1] == "TRANSACTION"):
+		return txBegin
+	case k == "START" && len(fields) > 1 && fields[1] == "TRANSACTION":
+		return txBegin
+	// ROLLBACK TO SAVEPOINT does not end the transaction.
+	case k == "COMMIT", k == "ROLLBACK" && (len(fields) == 1 || fields[1] != "TO"):
+		return txEnd
+	}
+	return ""
+}
+
+// StmtKind returns the leading keyword of the given statement in upper case, e.g.
+// CREATE, ALTER or INSERT. Leading spaces and comments are skipped, and MySQL-specific
+// executable comments (e.g. "/*!40101 SET NAMES utf8 */") are scanned for the keyword.
+// An empty string is returned if the statement does not start with a keyword.
+func StmtKind(stmt string) string {
+	for {
+		stmt = strings.TrimLeftFunc(stmt, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(stmt, "/*!"):
+			// Skip the optional version number of executable comments.
+			stmt = strings.TrimLeftFunc(stmt[3:], unicode.IsDigit)
+		case strings.HasPrefix(stmt, "/*"):
+			i := strings.Index(stmt, "*/")
+			if i == -1 {
+				return ""
+			}
+			stmt = stmt[i+2:]
+		case strings.HasPrefix(stmt, "#"), strings.HasPrefix(stmt, "--"):
+			i := strings.IndexByte(stmt, '\n')
+			if i == -1 {
+				return ""
+			}
+			stmt = stmt[i+1:]
+		default:
+			i := strings.IndexFunc(stmt, func(r rune) bool {
+				return !unicode.IsLetter(r) && r != '_'
+			})
+			if i == -1 {
+				i = len(stmt)
+			}
+			return strings.ToUpper(stmt[:i])
 		}
-		stmts = append(stmts, s)
 	}
 }
 
 type lex struct {
-	input    string
-	pos      int      // current phase position
-	total    int      // total bytes scanned so far
-	width    int      // size of latest rune
-	delim    string   // configured delimiter
-	comments []string // collected comments
+	input     string
+	pos       int      // current phase position
+	total     int      // total bytes scanned so far
+	width     int      // size of latest rune
+	delim     string   // configured delimiter
+	directive bool     // delimiter was configured by a directive
+	comments  []string // collected comments
+	simple    bool     // input has no comments
+	blank     bool     // blank lines separate statements
+	hash      bool     // '#' starts a single-line comment
+	exec      bool     // keep executable comments as statement text
+	routines  bool     // scan routine bodies as part of their statement
+	routine   bool     // current statement defines a routine
+	blocks    int      // depth of the BEGIN ... END blocks in the routine
 }
 
 const (
 	eos          = -1
 	delimiter    = ";"
 	delimiterCmd = "delimiter"
+	bom          = "\uFEFF"
 )
 
-func newLex(input string) (*lex, error) {
+func newLex(input string, opts ...StmtsOption) (*lex, error) {
 	l := &lex{input: input, delim: delimiter}
-	if d, ok := directive(input, directiveDelimiter, directivePrefixSQL); ok {
-		if err := l.setDelim(d); err != nil {
-			return nil, err
-		}
-		parts := strings.SplitN(input, "\n", 2)
-		if len(parts) == 1 {
-			return nil, fmt.Errorf("no input found after delimiter %q", d)
+	for _, opt := range opts {
+		opt(l)
+	}
+	// Files exported by some editors start with a UTF-8 BOM.
+	if strings.HasPrefix(input, bom) {
+		input = input[len(bom):]
+		l.input, l.total = input, len(bom)
+	}
+	// Directives are allowed only at the beginning of the file.
+	for {
+		if d, ok := directive(l.input, directiveDelimiter, directivePrefixSQL); ok {
+			if err := l.setDelim(d); err != nil {
+				return nil, err
+			}
+			l.directive = true
+			if err := l.skipLine(fmt.Sprintf("delimiter %q", d)); err != nil {
+				return nil, err
+			}
+		} else if d, ok := directive(l.input, directiveBatch, directivePrefixSQL); ok {
+			if d != batchBlankLine {
+				return nil, fmt.Errorf("unknown batch mode %q", d)
+			}
+			l.blank = true
+			if err := l.skipLine("batch directive"); err != nil {
+				return nil, err
+			}
+		} else {
+			break
 		}
-		l.input = parts[1]
 	}
+	l.simple = !l.blank && !l.routines && !strings.ContainsRune(l.input, '#') &&
+		!strings.Contains(l.input, "--") && !strings.Contains(l.input, "/*")
 	return l, nil
 }
 
@@ -96,6 +352,9 @@ func (l *lex) stmt() (*Stmt, error) {
 		text  string
 	)
 	l.skipSpaces()
+	if s, ok := l.simpleStmt(); ok {
+		return s, nil
+	}
 Scan:
 	for {
 		switch r := l.next(); {
@@ -128,21 +387,97 @@ Scan:
 				return nil, err
 			}
 		// Delimiters take precedence over comments.
-		case depth == 0 && strings.HasPrefix(l.input[l.pos-l.width:], l.delim):
+		case depth == 0 && l.blocks == 0 && strings.HasPrefix(l.input[l.pos-l.width:], l.delim):
 			l.addPos(len(l.delim) - l.width)
 			text = l.input[:l.pos]
 			break Scan
-		case r == '#':
+		// In blank-line mode, a blank line ends the statement.
+		case l.blank && depth == 0 && r == '\n' && l.blankLine():
+			text = l.input[:l.pos]
+			break Scan
+		case r == '#' && l.hash:
 			l.comment("#", "\n")
-		case r == '-' && l.next() == '-':
+		case l.routines && isIdentStart(r):
+			l.word()
+		case r == '-' && l.pick() == '-':
+			l.next()
 			l.comment("--", "\n")
-		case r == '/' && l.next() == '*':
+		case r == '/' && l.pick() == '*':
+			l.next()
 			l.comment("/*", "*/")
 		}
 	}
 	return l.emit(text), nil
 }
 
+// simpleStmt is a fast path for scanning statements of inputs without comments,
+// that are split by a single-byte delimiter. Quotes and parentheses (e.g. VALUES
+// lists) are scanned byte by byte. It reports false if the next statement requires
+// the full lexer, for example, on unclosed quotes or unbalanced parentheses.
+func (l *lex) simpleStmt() (*Stmt, bool) {
+	if !l.simple || len(l.delim) != 1 || l.input == "" ||
+		len(l.input) >= len(delimiterCmd) && strings.EqualFold(l.input[:len(delimiterCmd)], delimiterCmd) {
+		return nil, false
+	}
+	var (
+		depth int
+		delim = l.delim[0]
+		i     = 0
+	)
+	for ; i < len(l.input); i++ {
+		switch c := l.input[i]; {
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return nil, false
+			}
+			depth--
+		case c == '\'', c == '"', c == '`':
+			j := skipQuoteByte(l.input, i+1, c)
+			if j == -1 {
+				return nil, false
+			}
+			i = j
+		case c == delim && depth == 0:
+			l.addPos(i + 1)
+			return l.emit(l.input[:i+1]), true
+		}
+	}
+	if depth > 0 {
+		return nil, false
+	}
+	l.addPos(i)
+	return l.emit(l.input), true
+}
+
+// skipQuoteByte returns the index of the closing quote in s, starting
+// from position i, or -1 if the quote is not closed. It is the byte
+// equivalent of skipQuote, used by the fast path.
+func skipQuoteByte(s string, i int, quote byte) int {
+	for ; i < len(s); i++ {
+		switch c := s[i]; {
+		// Backslash escapes are not supported in quoted identifiers.
+		case c == '\\' && quote != '`':
+			i++
+		case c == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// skipLine skips the first line of the input. The "what"
+// describes the line content in case no input follows it.
+func (l *lex) skipLine(what string) error {
+	parts := strings.SplitN(l.input, "\n", 2)
+	if len(parts) == 1 {
+		return fmt.Errorf("no input found after %s", what)
+	}
+	l.input = parts[1]
+	return nil
+}
+
 func (l *lex) next() rune {
 	if l.pos >= len(l.input) {
 		return eos
@@ -154,12 +489,19 @@ func (l *lex) next() rune {
 }
 
 func (l *lex) pick() rune {
-	p, w := l.pos, l.width
+	p, w, t := l.pos, l.width, l.total
 	r := l.next()
-	l.pos, l.width = p, w
+	l.pos, l.width, l.total = p, w, t
 	return r
 }
 
+// blankLine reports if the line that starts at the current position is blank.
+// i.e. it contains only whitespace characters (e.g. " ", "\t" or "\r").
+func (l *lex) blankLine() bool {
+	i := strings.IndexByte(l.input[l.pos:], '\n')
+	return i != -1 && strings.TrimSpace(l.input[l.pos:l.pos+i]) == ""
+}
+
 func (l *lex) addPos(p int) {
 	l.pos += p
 	l.total += p
@@ -170,7 +512,8 @@ func (l *lex) skipQuote(quote rune) error {
 		switch r := l.next(); {
 		case r == eos:
 			return fmt.Errorf("unclosed quote %q", quote)
-		case r == '\\':
+		// Backslash escapes are not supported in quoted identifiers.
+		case r == '\\' && quote != '`':
 			l.next()
 		case r == quote:
 			return nil
@@ -184,8 +527,9 @@ func (l *lex) comment(left, right string) {
 	if i == -1 {
 		return
 	}
-	// If the comment reside inside a statement, collect it.
-	if l.pos != len(left) {
+	// If the comment reside inside a statement, or it is a MySQL executable
+	// comment (e.g. "/*!50003 CREATE ... */"), collect it as statement text.
+	if l.pos != len(left) || l.exec && left == "/*" && strings.HasPrefix(l.input[l.pos:], "!") {
 		l.addPos(i + len(right))
 		return
 	}
@@ -202,6 +546,72 @@ func (l *lex) comment(left, right string) {
 	l.skipSpaces()
 }
 
+// recover skips the current statement up to the next delimiter,
+// ignoring quotes and parentheses, and returns its error.
+func (l *lex) recover(err error) *StmtError {
+	l.total -= l.pos
+	n := len(l.input)
+	if i := strings.Index(l.input, l.delim); i != -1 {
+		n = i + len(l.delim)
+	}
+	e := &StmtError{Pos: l.total, Text: strings.TrimSpace(l.input[:n]), Err: err}
+	l.input, l.pos, l.comments = l.input[n:], 0, nil
+	l.routine, l.blocks = false, 0
+	l.total += n
+	return e
+}
+
+// reRoutine matches the start of statements that define stored routines, triggers and events.
+var reRoutine = regexp.MustCompile(`(?i)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:DEFINER\s*=\s*\S+\s+)?(?:AGGREGATE\s+)?(?:PROCEDURE|FUNCTION|TRIGGER|EVENT)\b`)
+
+// word scans the word that starts at the current position, and tracks
+// the nesting of BEGIN ... END blocks in case the statement defines a routine.
+func (l *lex) word() {
+	start := l.pos - l.width
+	end := strings.IndexFunc(l.input[start:], func(r rune) bool { return !isIdentRune(r) })
+	if end == -1 {
+		end = len(l.input) - start
+	}
+	l.addPos(start + end - l.pos)
+	if start == 0 {
+		l.routine = reRoutine.MatchString(l.input)
+	}
+	// Qualified names (e.g. t.end or `t`.end) are not keywords.
+	if !l.routine || start > 0 && (l.input[start-1] == '.' || l.input[start-1] == '`') {
+		return
+	}
+	switch w := l.input[start : start+end]; {
+	// CASE statements end with END CASE,
+	// and CASE expressions end with END.
+	case strings.EqualFold(w, "BEGIN"), strings.EqualFold(w, "CASE"):
+		l.blocks++
+	case strings.EqualFold(w, "END") && l.blocks > 0:
+		rest := strings.TrimLeftFunc(l.input[l.pos:], unicode.IsSpace)
+		next := rest
+		if i := strings.IndexFunc(next, func(r rune) bool { return !isIdentRune(r) }); i != -1 {
+			next = next[:i]
+		}
+		switch strings.ToUpper(next) {
+		// END IF, END LOOP, END WHILE and END REPEAT close other compound statements.
+		case "IF", "LOOP", "WHILE", "REPEAT":
+		// Skip the CASE keyword of END CASE, as it does not open a new block.
+		case "CASE":
+			l.addPos(len(l.input) - l.pos - len(rest) + len(next))
+			l.blocks--
+		default:
+			l.blocks--
+		}
+	}
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
 func (l *lex) skipSpaces() {
 	n := len(l.input)
 	l.input = strings.TrimLeftFunc(l.input, unicode.IsSpace)
@@ -213,6 +623,7 @@ func (l *lex) emit(text string) *Stmt {
 	l.input = l.input[l.pos:]
 	l.pos = 0
 	l.comments = nil
+	l.routine, l.blocks = false, 0
 	// Trim custom delimiter.
 	if l.delim != delimiter {
 		s.Text = strings.TrimSuffix(s.Text, l.delim)
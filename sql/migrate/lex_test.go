@@ -5,6 +5,9 @@
 package migrate
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,8 +23,13 @@ func TestLocalFile_Stmts(t *testing.T) {
 	files, err := dir.Files()
 	require.NoError(t, err)
 	for _, f := range files {
-		stmts, err := f.Stmts()
+		// The test files use MySQL '#' and executable comments.
+		s, err := Stmts(string(f.Bytes()), WithHashComments(true), WithExecutableComments(true))
 		require.NoError(t, err)
+		stmts := make([]string, len(s))
+		for i := range s {
+			stmts[i] = s[i].Text
+		}
 		buf, err := os.ReadFile(filepath.Join(path, f.Name()+".golden"))
 		require.NoError(t, err)
 		require.Equalf(t, string(buf), strings.Join(stmts, "\n-- end --\n"), "mismatched statements in file %q", f.Name())
@@ -65,7 +73,7 @@ atlas:lint not a directive
 */
 cmd6;
 `
-	stmts, err := NewLocalFile("f", []byte(f)).StmtDecls()
+	stmts, err := Stmts(f, WithHashComments(true))
 	require.NoError(t, err)
 	require.Len(t, stmts, 7)
 
@@ -99,3 +107,446 @@ cmd6;
 	require.Equal(t, []string{"error"}, stmts[6].Directive("lint"))
 	require.Equal(t, []string{"DS101"}, stmts[6].Directive("nolint"))
 }
+
+func TestStmtKind(t *testing.T) {
+	for stmt, kind := range map[string]string{
+		"CREATE TABLE t (id int);":                             "CREATE",
+		"  alter table t add column c int;":                    "ALTER",
+		"insert into t values (1);":                            "INSERT",
+		"/* comment */ DROP TABLE t;":                          "DROP",
+		"/* multi\nline */\n/* another */\nupdate t set a = 1": "UPDATE",
+		"-- comment\n# another\nDELETE FROM t;":                "DELETE",
+		"/*!40101 SET NAMES utf8 */;":                          "SET",
+		"/*! CREATE TABLE t (id int) */;":                      "CREATE",
+		"SELECT/*+ MAX_EXECUTION_TIME(1000) */ * FROM t;":      "SELECT",
+		"/* unclosed":       "",
+		"-- only a comment": "",
+		"":                  "",
+		"(SELECT 1);":       "",
+	} {
+		require.Equal(t, kind, StmtKind(stmt), stmt)
+	}
+}
+
+func TestCommentedStmts(t *testing.T) {
+	input := `-- Create the users table.
+-- It holds the registered users.
+CREATE TABLE users (id int);
+/* The posts table. */
+CREATE TABLE posts (id int, user_id int);
+CREATE INDEX idx ON posts (user_id);
+# Seed data.
+INSERT INTO users VALUES (1);`
+	stmts, err := CommentedStmts(input, WithHashComments(true))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"-- Create the users table.\n-- It holds the registered users.\nCREATE TABLE users (id int);",
+		"/* The posts table. */\nCREATE TABLE posts (id int, user_id int);",
+		"CREATE INDEX idx ON posts (user_id);",
+		"# Seed data.\nINSERT INTO users VALUES (1);",
+	}, stmts)
+	require.Equal(t, input, strings.Join(stmts, "\n"))
+
+	// Without hash comments, '#' lines are part of the statement text.
+	stmts, err = CommentedStmts("# File header.\n\nSELECT 1;", WithHashComments(true))
+	require.NoError(t, err)
+	require.Equal(t, []string{"SELECT 1;"}, stmts)
+	stmts, err = CommentedStmts("# File header.\n\nSELECT 1;")
+	require.NoError(t, err)
+	require.Equal(t, []string{"# File header.\n\nSELECT 1;"}, stmts)
+
+	// Detached comments are not attached to the next statement.
+	stmts, err = CommentedStmts("-- File header.\n\n-- Create t.\nCREATE TABLE t (c int);")
+	require.NoError(t, err)
+	require.Equal(t, []string{"-- Create t.\nCREATE TABLE t (c int);"}, stmts)
+}
+
+func TestStmts_HashComments(t *testing.T) {
+	input := "# Create the table.\nCREATE TABLE t (c int);\nSELECT c # 2 FROM t;\nDROP TABLE t;"
+	stmts, err := Stmts(input, WithHashComments(true))
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, []string{"# Create the table.\n"}, stmts[0].Comments)
+	require.Equal(t, "CREATE TABLE t (c int);", stmts[0].Text)
+	// The rest of the line, including the delimiter, is a comment.
+	require.Equal(t, "SELECT c # 2 FROM t;\nDROP TABLE t;", stmts[1].Text)
+
+	// Hash comments are disabled by default, as in PostgreSQL, '#' is the bitwise XOR operator.
+	stmts, err = Stmts(input)
+	require.NoError(t, err)
+	require.Len(t, stmts, 3)
+	require.Empty(t, stmts[0].Comments)
+	require.Equal(t, "# Create the table.\nCREATE TABLE t (c int);", stmts[0].Text)
+	require.Equal(t, "SELECT c # 2 FROM t;", stmts[1].Text)
+	require.Equal(t, "DROP TABLE t;", stmts[2].Text)
+
+	// PostgreSQL operators survive the lexing of migration files.
+	f := NewLocalFile("1.sql", []byte("SELECT 5 # 3;\nSELECT '{\"a\":{\"b\":1}}'::jsonb #> '{a,b}';\n"))
+	texts, err := f.Stmts()
+	require.NoError(t, err)
+	require.Equal(t, []string{"SELECT 5 # 3;", `SELECT '{"a":{"b":1}}'::jsonb #> '{a,b}';`}, texts)
+}
+
+func TestStmts_ExecutableComments(t *testing.T) {
+	input := "/*!40101 SET NAMES utf8mb4 */;\nCREATE TABLE t (c int);"
+	stmts, err := Stmts(input)
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, []string{"/*!40101 SET NAMES utf8mb4 */"}, stmts[0].Comments)
+	require.Equal(t, ";", stmts[0].Text)
+	require.Equal(t, "CREATE TABLE t (c int);", stmts[1].Text)
+
+	stmts, err = Stmts(input, WithExecutableComments(true))
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Empty(t, stmts[0].Comments)
+	require.Equal(t, "/*!40101 SET NAMES utf8mb4 */;", stmts[0].Text)
+	require.Equal(t, "CREATE TABLE t (c int);", stmts[1].Text)
+}
+
+func TestStmtsRecover(t *testing.T) {
+	input := "CREATE TABLE t1(c int);\nINSERT INTO t1 VALUES ('a);\nINSERT INTO t1 VALUES (1);\nINSERT INTO t1 VALUES (2));\nDROP TABLE t1;"
+	stmts, errs := StmtsRecover(input)
+	require.Len(t, stmts, 3)
+	require.Equal(t, "CREATE TABLE t1(c int);", stmts[0].Text)
+	require.Equal(t, "INSERT INTO t1 VALUES (1);", stmts[1].Text)
+	require.Equal(t, "DROP TABLE t1;", stmts[2].Text)
+	for _, s := range stmts {
+		require.Equal(t, s.Text, input[s.Pos:s.Pos+len(s.Text)])
+	}
+	require.Len(t, errs, 2)
+	require.Equal(t, 24, errs[0].Pos)
+	require.Equal(t, "INSERT INTO t1 VALUES ('a);", errs[0].Text)
+	require.EqualError(t, errs[0], `statement at position 24: unclosed quote '\''`)
+	require.Equal(t, 79, errs[1].Pos)
+	require.Equal(t, "INSERT INTO t1 VALUES (2));", errs[1].Text)
+	require.EqualError(t, errs[1].Err, "unexpected ')' at position 26")
+
+	stmts, errs = StmtsRecover("SELECT 1;\nSELECT ('a")
+	require.Len(t, stmts, 1)
+	require.Len(t, errs, 1)
+	require.Equal(t, "SELECT ('a", errs[0].Text)
+
+	stmts, errs = StmtsRecover(input[:23])
+	require.Len(t, stmts, 1)
+	require.Empty(t, errs)
+
+	// Options are applied like in Stmts.
+	stmts, errs = StmtsRecover("# SELECT ('a\nSELECT 1;", WithHashComments(true))
+	require.Len(t, stmts, 1)
+	require.Empty(t, errs)
+	require.Equal(t, "SELECT 1;", stmts[0].Text)
+}
+
+func TestStmts_RoutineBodies(t *testing.T) {
+	input := `CREATE TABLE t(c int);
+CREATE PROCEDURE p(IN n int)
+BEGIN
+  DECLARE i int DEFAULT 0;
+  lbl: BEGIN
+    WHILE i < n DO
+      IF i % 2 = 0 THEN
+        INSERT INTO t VALUES (i);
+      END IF;
+      SET i = i + 1;
+    END WHILE;
+  END lbl;
+  CASE n
+    WHEN 0 THEN SELECT 'zero';
+    ELSE SELECT CASE WHEN n > 0 THEN 'positive' ELSE 'negative' END;
+  END CASE;
+END;
+CREATE DEFINER=` + "`root`@`%`" + ` TRIGGER tr BEFORE INSERT ON t FOR EACH ROW BEGIN
+  SET NEW.c = NEW.c + 1;
+END;
+CREATE FUNCTION f() RETURNS int DETERMINISTIC RETURN 1;
+CALL p(10);`
+	stmts, err := Stmts(input, WithRoutineBodies(true))
+	require.NoError(t, err)
+	require.Len(t, stmts, 5)
+	require.Equal(t, "CREATE TABLE t(c int);", stmts[0].Text)
+	require.True(t, strings.HasPrefix(stmts[1].Text, "CREATE PROCEDURE p(IN n int)\nBEGIN\n"))
+	require.True(t, strings.HasSuffix(stmts[1].Text, "  END CASE;\nEND;"))
+	require.True(t, strings.HasPrefix(stmts[2].Text, "CREATE DEFINER=`root`@`%` TRIGGER tr"))
+	require.True(t, strings.HasSuffix(stmts[2].Text, "SET NEW.c = NEW.c + 1;\nEND;"))
+	require.Equal(t, "CREATE FUNCTION f() RETURNS int DETERMINISTIC RETURN 1;", stmts[3].Text)
+	require.Equal(t, "CALL p(10);", stmts[4].Text)
+	for _, s := range stmts {
+		require.Equal(t, s.Text, input[s.Pos:s.Pos+len(s.Text)])
+	}
+
+	// Qualified names are not block keywords.
+	stmts, err = Stmts("CREATE PROCEDURE p() BEGIN SELECT t.end, `t`.begin FROM t; SELECT 1; END;\nSELECT 2;", WithRoutineBodies(true))
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "CREATE PROCEDURE p() BEGIN SELECT t.end, `t`.begin FROM t; SELECT 1; END;", stmts[0].Text)
+	require.Equal(t, "SELECT 2;", stmts[1].Text)
+
+	// Routine bodies are split by default.
+	stmts, err = Stmts(input)
+	require.NoError(t, err)
+	require.Greater(t, len(stmts), 5)
+
+	// Custom delimiters are still supported.
+	stmts, err = Stmts("-- atlas:delimiter $$\nCREATE PROCEDURE p() BEGIN SELECT 1; END$$\nCALL p()$$", WithRoutineBodies(true))
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "CREATE PROCEDURE p() BEGIN SELECT 1; END", stmts[0].Text)
+	require.Equal(t, "CALL p()", stmts[1].Text)
+}
+
+func TestDelimiter(t *testing.T) {
+	d, ok, err := Delimiter("-- atlas:delimiter $$\nCREATE TABLE t(c int)$$")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "$$", d)
+
+	d, ok, err = Delimiter("\uFEFF-- atlas:batch blankline\n-- atlas:delimiter \\n\\n\nCREATE TABLE t(c int)")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "\n\n", d)
+
+	// The default delimiter, set explicitly.
+	d, ok, err = Delimiter("-- atlas:delimiter ;\nCREATE TABLE t(c int);")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, ";", d)
+
+	// Absent directive.
+	d, ok, err = Delimiter("CREATE TABLE t(c int);\n-- atlas:delimiter $$\n")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Empty(t, d)
+
+	// Malformed directives.
+	_, _, err = Delimiter("-- atlas:delimiter")
+	require.Error(t, err)
+	_, _, err = Delimiter("-- atlas:batch unknown\n-- atlas:delimiter $$\nCREATE TABLE t(c int)$$")
+	require.EqualError(t, err, `unknown batch mode "unknown"`)
+}
+
+func TestTxStmts(t *testing.T) {
+	batches, err := TxStmts(`CREATE TABLE t1(c int);
+BEGIN;
+INSERT INTO t1 VALUES (1);
+INSERT INTO t1 VALUES (2);
+COMMIT;
+-- Auto-committed.
+CREATE TABLE t2(c int);
+START TRANSACTION;
+SAVEPOINT s1;
+UPDATE t1 SET c = 3;
+ROLLBACK TO SAVEPOINT s1;
+ROLLBACK;
+BEGIN WORK;
+DELETE FROM t1;
+COMMIT WORK;
+DROP TABLE t2;
+`)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"CREATE TABLE t1(c int);"},
+		{"BEGIN;", "INSERT INTO t1 VALUES (1);", "INSERT INTO t1 VALUES (2);", "COMMIT;"},
+		{"CREATE TABLE t2(c int);"},
+		{"START TRANSACTION;", "SAVEPOINT s1;", "UPDATE t1 SET c = 3;", "ROLLBACK TO SAVEPOINT s1;", "ROLLBACK;"},
+		{"BEGIN WORK;", "DELETE FROM t1;", "COMMIT WORK;"},
+		{"DROP TABLE t2;"},
+	}, batches)
+
+	// Compound statements are not transactions.
+	batches, err = TxStmts("-- atlas:delimiter //\nCREATE PROCEDURE p() BEGIN SELECT 1; END//\nCALL p()//")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"CREATE PROCEDURE p() BEGIN SELECT 1; END"}, {"CALL p()"}}, batches)
+	batches, err = TxStmts("CREATE PROCEDURE p() BEGIN SELECT 1; END;\nCALL p();", WithRoutineBodies(true))
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"CREATE PROCEDURE p() BEGIN SELECT 1; END;"}, {"CALL p();"}}, batches)
+
+	_, err = TxStmts("BEGIN;\nINSERT INTO t1 VALUES (1);")
+	require.EqualError(t, err, "unterminated transaction: missing COMMIT or ROLLBACK")
+	_, err = TxStmts("BEGIN;\nBEGIN;\nCOMMIT;")
+	require.EqualError(t, err, "nested transaction at position 7")
+}
+
+func TestStmts_BOM(t *testing.T) {
+	stmts, err := Stmts("\uFEFFCREATE TABLE t1(c int);\nCREATE TABLE t2(c int);")
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "CREATE TABLE t1(c int);", stmts[0].Text)
+	require.Equal(t, 3, stmts[0].Pos, "position in file including the BOM")
+
+	stmts, err = Stmts("\uFEFF-- atlas:delimiter \\n\\n\nCREATE TABLE t1(c int)\n\nCREATE TABLE t2(c int)")
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "CREATE TABLE t1(c int)", stmts[0].Text)
+	require.Equal(t, "CREATE TABLE t2(c int)", stmts[1].Text)
+}
+
+func TestStmts_Simple(t *testing.T) {
+	for _, input := range []string{
+		"INSERT INTO t SET a = 1;\nINSERT INTO t SET a = 2;\n\n  INSERT INTO t SET a = 3",
+		"cmd1;;  cmd2;\n\n",
+		"DELIMITER |\ncmd1|\ncmd2|\nDELIMITER ;\ncmd3;",
+		"-- atlas:delimiter \\n\ncmd1\ncmd2\n\ncmd3",
+		"  \n\t ",
+		"INSERT INTO t VALUES (1, 'a;b', \"c\\\"d;\");\nINSERT INTO t (`a;`, b) VALUES (1, ('x')), (2, NULL);",
+		"INSERT INTO t VALUES (1, 'it\\'s;', -1, 2/3);\nINSERT INTO t VALUES (2, '2020-01-01', 1-(2))",
+		"SELECT ';' FROM (SELECT 1);\nSELECT 'unclosed;",
+		"SELECT (1;\nSELECT 2;",
+		"SELECT 1);\nSELECT 2;",
+	} {
+		l, err := newLex(input)
+		require.NoError(t, err)
+		require.True(t, l.simple)
+		fast, err1 := lexAll(l)
+		l, err = newLex(input)
+		require.NoError(t, err)
+		l.simple = false
+		slow, err2 := lexAll(l)
+		require.Equal(t, err2, err1, input)
+		require.Equal(t, slow, fast, input)
+	}
+	for _, input := range []string{
+		"INSERT INTO t VALUES (1); -- comment",
+		"INSERT INTO t VALUES (1); /* comment */",
+		"INSERT INTO t VALUES ('#');",
+	} {
+		l, err := newLex(input)
+		require.NoError(t, err)
+		require.False(t, l.simple)
+	}
+}
+
+func lexAll(l *lex) ([]*Stmt, error) {
+	var stmts []*Stmt
+	for {
+		s, err := l.stmt()
+		if err == io.EOF {
+			return stmts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, s)
+	}
+}
+
+func BenchmarkStmts(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; sb.Len() < 10<<20; i++ {
+		fmt.Fprintf(&sb, "INSERT INTO users (id, name, age) VALUES (%d, 'a8m', %d);\n", i, i%100)
+	}
+	input := sb.String()
+	for _, simple := range []bool{false, true} {
+		name := "Full"
+		if simple {
+			name = "Simple"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				l, err := newLex(input)
+				if err != nil {
+					b.Fatal(err)
+				}
+				l.simple = l.simple && simple
+				if _, err := lexAll(l); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStmtsFunc(t *testing.T) {
+	var stmts []string
+	err := StmtsFunc("cmd1;\ncmd2;\n-- comment\ncmd3;", func(s string) error {
+		stmts = append(stmts, s)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"cmd1;", "cmd2;", "cmd3;"}, stmts)
+
+	// Early abort.
+	stmts = nil
+	abort := errors.New("abort")
+	err = StmtsFunc("cmd1;\ncmd2;\ncmd3;", func(s string) error {
+		stmts = append(stmts, s)
+		if len(stmts) == 2 {
+			return abort
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, abort)
+	require.Equal(t, []string{"cmd1;", "cmd2;"}, stmts)
+
+	// Lexing errors are propagated after the preceding statements were passed.
+	stmts = nil
+	err = StmtsFunc("cmd1;\ncmd2 ('unclosed;", func(s string) error {
+		stmts = append(stmts, s)
+		return nil
+	})
+	require.EqualError(t, err, `unclosed quote '\''`)
+	require.Equal(t, []string{"cmd1;"}, stmts)
+
+	// Options are passed to the lexer.
+	stmts = nil
+	err = StmtsFunc("SELECT a # b;\nCREATE PROCEDURE p() BEGIN SELECT 1; END;", func(s string) error {
+		stmts = append(stmts, s)
+		return nil
+	}, WithHashComments(false), WithRoutineBodies(true))
+	require.NoError(t, err)
+	require.Equal(t, []string{"SELECT a # b;", "CREATE PROCEDURE p() BEGIN SELECT 1; END;"}, stmts)
+}
+
+func TestStmts_BlankLine(t *testing.T) {
+	input := `CREATE TABLE t1 (
+  c int
+)
+
+CREATE TABLE t2 (c int);
+INSERT INTO t2 VALUES (1);
+
+-- comment
+INSERT INTO t1 VALUES ('a
+
+b')`
+	// By default, statements are separated by the delimiter only.
+	stmts, err := Stmts(input)
+	require.NoError(t, err)
+	require.Len(t, stmts, 3)
+	require.Equal(t, "CREATE TABLE t1 (\n  c int\n)\n\nCREATE TABLE t2 (c int);", stmts[0].Text)
+
+	stmts, err = Stmts("-- atlas:batch blankline\n" + input)
+	require.NoError(t, err)
+	require.Len(t, stmts, 4)
+	require.Equal(t, "CREATE TABLE t1 (\n  c int\n)", stmts[0].Text)
+	require.Equal(t, "CREATE TABLE t2 (c int);", stmts[1].Text)
+	require.Equal(t, "INSERT INTO t2 VALUES (1);", stmts[2].Text)
+	require.Equal(t, "INSERT INTO t1 VALUES ('a\n\nb')", stmts[3].Text, "blank lines inside quotes are not separators")
+	require.Equal(t, []string{"-- comment\n"}, stmts[3].Comments)
+
+	// Both directives can be combined.
+	stmts, err = Stmts("-- atlas:delimiter $$\n-- atlas:batch blankline\ncmd1 $$ cmd2\n\ncmd3")
+	require.NoError(t, err)
+	require.Len(t, stmts, 3)
+	require.Equal(t, "cmd1", stmts[0].Text)
+	require.Equal(t, "cmd2", stmts[1].Text)
+	require.Equal(t, "cmd3", stmts[2].Text)
+
+	// Lines that contain only whitespace are blank lines as well.
+	for _, input := range []string{
+		"-- atlas:batch blankline\r\ncmd1\r\n\r\ncmd2\r\n\r\ncmd3",
+		"-- atlas:batch blankline\ncmd1\n  \ncmd2\n\t\ncmd3",
+		"-- atlas:batch blankline\r\ncmd1\r\n \t\r\ncmd2\n\ncmd3",
+	} {
+		stmts, err = Stmts(input)
+		require.NoError(t, err)
+		require.Len(t, stmts, 3, input)
+		require.Equal(t, "cmd1", stmts[0].Text)
+		require.Equal(t, "cmd2", stmts[1].Text)
+		require.Equal(t, "cmd3", stmts[2].Text)
+	}
+
+	_, err = Stmts("-- atlas:batch go\ncmd")
+	require.EqualError(t, err, `unknown batch mode "go"`)
+}
@@ -104,6 +104,13 @@ type (
 	// PlanOption allows configuring a drivers' plan using functional arguments.
 	PlanOption func(*PlanOptions)
 
+	// StmtScanner is an optional interface implemented by drivers that scan the
+	// statements of migration files using the lexing rules of their dialect
+	// (e.g. MySQL '#' comments), instead of the default ones.
+	StmtScanner interface {
+		ScanStmts(input string) ([]*Stmt, error)
+	}
+
 	// StateReader wraps the method for reading a database/schema state.
 	// The types below provides a few builtin options for reading a state
 	// from a migration directory, a static object (e.g. a parsed file).
@@ -121,6 +128,24 @@ func (f StateReaderFunc) ReadState(ctx context.Context) (*schema.Realm, error) {
 	return f(ctx)
 }
 
+// FileStmts returns the statements of the migration file, scanned using the
+// StmtScanner of the driver, if it is implemented, or File.Stmts otherwise.
+func FileStmts(drv Driver, f File) ([]string, error) {
+	sc, ok := drv.(StmtScanner)
+	if !ok {
+		return f.Stmts()
+	}
+	s, err := sc.ScanStmts(string(f.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	stmts := make([]string, len(s))
+	for i := range s {
+		stmts[i] = s[i].Text
+	}
+	return stmts, nil
+}
+
 // ErrNoPlan is returned by Plan when there is no change between the two states.
 var ErrNoPlan = errors.New("sql/migrate: no plan for matched states")
 
@@ -613,7 +638,7 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 	if err != nil {
 		return fmt.Errorf("sql/migrate: execute: scanning checksum from %q: %w", m.Name(), err)
 	}
-	stmts, err := m.Stmts()
+	stmts, err := FileStmts(e.drv, m)
 	if err != nil {
 		return fmt.Errorf("sql/migrate: execute: scanning statements from %q: %w", m.Name(), err)
 	}
@@ -0,0 +1,7 @@
+-- atlas:delimiter ;;
+
+CREATE TABLE `t;;1` (`c;;` int, `d\` int);;
+
+INSERT INTO `t;;1` (`c;;`) VALUES (1);;
+
+SELECT `c;;` FROM `t;;1`;;
@@ -0,0 +1,23 @@
+-- Routines and triggers section, as produced by mysqldump --routines --triggers.
+
+/*!40101 SET NAMES utf8mb4 */;
+CREATE TABLE `users` (
+  `id` int NOT NULL,
+  `name` varchar(255) DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB;
+
+/*!50003 SET @saved_sql_mode = @@sql_mode */ ;
+DELIMITER ;;
+/*!50003 CREATE*/ /*!50017 DEFINER=`root`@`%`*/ /*!50003 TRIGGER `users_bi` BEFORE INSERT ON `users` FOR EACH ROW BEGIN
+  SET NEW.name = TRIM(NEW.name);
+END */;;
+DELIMITER ;
+/*!50003 SET sql_mode = @saved_sql_mode */ ;
+
+DELIMITER ;;
+CREATE DEFINER=`root`@`%` PROCEDURE `count_users`(OUT total INT)
+BEGIN
+  SELECT COUNT(*) INTO total FROM `users`;
+END ;;
+DELIMITER ;
@@ -0,0 +1,29 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import "ariga.io/atlas/sql/schema"
+
+// ResolveCharset returns the effective charset and collation of the column, as
+// resolved by MySQL: an explicit column charset or collation, or the ones inherited
+// from its table, or from the table's schema. In case the nearest element defines
+// only one of them, the other is derived from it using the MySQL 8 defaults, e.g.
+// a column defined with "CHARSET latin1" uses the "latin1_swedish_ci" collation.
+func ResolveCharset(c *schema.Column, t *schema.Table, s *schema.Schema) (charset, collation string) {
+	var levels [][]schema.Attr
+	levels = append(levels, c.Attrs)
+	if t != nil {
+		levels = append(levels, t.Attrs)
+	}
+	if s != nil {
+		levels = append(levels, s.Attrs)
+	}
+	for _, attrs := range levels {
+		if ch, co, ok := impliedCharset(attrs); ok {
+			return ch, co
+		}
+	}
+	return "", ""
+}
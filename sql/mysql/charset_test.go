@@ -0,0 +1,54 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCharset(t *testing.T) {
+	s := schema.New("test").SetCharset("utf8mb4").SetCollation("utf8mb4_bin")
+	tbl := schema.NewTable("t")
+	c := schema.NewStringColumn("c", TypeVarchar)
+	s.AddTables(tbl.AddColumns(c))
+
+	// Inherited from the schema.
+	ch, co := ResolveCharset(c, tbl, s)
+	require.Equal(t, "utf8mb4", ch)
+	require.Equal(t, "utf8mb4_bin", co)
+
+	// Inherited from the table.
+	tbl.SetCharset("latin1").SetCollation("latin1_bin")
+	ch, co = ResolveCharset(c, tbl, s)
+	require.Equal(t, "latin1", ch)
+	require.Equal(t, "latin1_bin", co)
+
+	// Defined on the column.
+	c.SetCharset("utf8mb3").SetCollation("utf8mb3_bin")
+	ch, co = ResolveCharset(c, tbl, s)
+	require.Equal(t, "utf8mb3", ch)
+	require.Equal(t, "utf8mb3_bin", co)
+
+	// Only the charset is defined on the column.
+	c.Attrs = []schema.Attr{&schema.Charset{V: "latin1"}}
+	ch, co = ResolveCharset(c, tbl, s)
+	require.Equal(t, "latin1", ch)
+	require.Equal(t, "latin1_swedish_ci", co)
+
+	// Only the collation is defined on the column.
+	c.Attrs = []schema.Attr{&schema.Collation{V: "utf8mb4_general_ci"}}
+	ch, co = ResolveCharset(c, tbl, s)
+	require.Equal(t, "utf8mb4", ch)
+	require.Equal(t, "utf8mb4_general_ci", co)
+
+	// Nothing is defined.
+	ch, co = ResolveCharset(schema.NewIntColumn("id", TypeInt), nil, nil)
+	require.Empty(t, ch)
+	require.Empty(t, co)
+}
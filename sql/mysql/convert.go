@@ -13,9 +13,37 @@ import (
 	"ariga.io/atlas/sql/schema"
 )
 
+// FormatOption configures the formatting of types.
+type FormatOption func(*formatConfig)
+
+// formatConfig holds the configuration of the type formatting.
+type formatConfig struct {
+	omitDefaults bool
+}
+
+// OmitDefaults configures the formatting to omit the size, precision and scale
+// of types in case they are equal to their MySQL defaults. For example:
+//
+//	FormatType(&schema.DecimalType{T: "decimal", Precision: 10}, OmitDefaults())	// decimal
+func OmitDefaults() FormatOption {
+	return func(c *formatConfig) {
+		c.omitDefaults = true
+	}
+}
+
+// newFormatConfig returns the format configuration of the given options.
+func newFormatConfig(opts []FormatOption) *formatConfig {
+	c := &formatConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
 // FormatType converts schema type to its column form in the database.
 // An error is returned if the type cannot be recognized.
-func FormatType(t schema.Type) (string, error) {
+func FormatType(t schema.Type, opts ...FormatOption) (string, error) {
+	cfg := newFormatConfig(opts)
 	var f string
 	switch t := t.(type) {
 	case *BitType:
@@ -46,6 +74,8 @@ func FormatType(t schema.Type) (string, error) {
 			return "", fmt.Errorf("mysql: decimal type must have precision > 0 and scale >= 0: %d, %d", p, s)
 		case p < s:
 			return "", fmt.Errorf("mysql: decimal type must have precision >= scale: %d < %d", p, s)
+		case cfg.omitDefaults && defaultDecimal(t):
+			f = TypeDecimal
 		case p == 0 && s == 0:
 			// The default value for precision is 10 (i.e. decimal(0,0) = decimal(10)).
 			p = 10
@@ -73,7 +103,14 @@ func FormatType(t schema.Type) (string, error) {
 		}
 	case *schema.IntegerType:
 		f = strings.ToLower(t.T)
-		if t.Unsigned {
+		// The ZEROFILL attribute implies UNSIGNED, and
+		// its display width is used for padding values.
+		if z := (ZeroFill{}); sqlx.Has(t.Attrs, &z) {
+			if w := (DisplayWidth{}); sqlx.Has(t.Attrs, &w) && w.N > 0 {
+				f += fmt.Sprintf("(%d)", w.N)
+			}
+			f += " unsigned zerofill"
+		} else if t.Unsigned {
 			f += " unsigned"
 		}
 	case *schema.JSONType:
@@ -110,9 +147,83 @@ func FormatType(t schema.Type) (string, error) {
 	return f, nil
 }
 
+// defaultDecimal reports if the precision and scale of the decimal type are
+// equal to their MySQL defaults. i.e. DECIMAL, DECIMAL(10) or DECIMAL(10,0).
+func defaultDecimal(t *schema.DecimalType) bool {
+	return (t.Precision == 0 || t.Precision == 10) && t.Scale == 0
+}
+
 // ParseType returns the schema.Type value represented by the given raw type.
-// The raw value is expected to follow the format in MySQL information schema.
+// The raw value is expected to follow the format in MySQL information schema,
+// e.g. "varchar(255)", "int unsigned" or "enum('a','b')".
+//
+// Unlike the types returned by inspection, an error is returned in case the
+// type is unknown. If the type name is close to one of the known MySQL types,
+// the error suggests it. For example:
+//
+//	ParseType("varchart(255)")	// mysql: unknown type "varchart"; did you mean "varchar"?
 func ParseType(raw string) (schema.Type, error) {
+	t, err := parseType(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u, ok := t.(*schema.UnsupportedType); ok {
+		if s, ok := suggestType(u.T); ok {
+			return nil, fmt.Errorf("mysql: unknown type %q; did you mean %q?", u.T, s)
+		}
+		return nil, fmt.Errorf("mysql: unknown type %q", u.T)
+	}
+	return t, nil
+}
+
+// suggestType returns the registered type name that is
+// closest to the given name, if it is close enough.
+func suggestType(name string) (string, bool) {
+	var (
+		best string
+		// Allow one edit for every 3 characters.
+		maxD = len(name)/3 + 1
+	)
+	for _, s := range TypeRegistry.Specs() {
+		if d := editDistance(name, s.Name); d <= maxD {
+			best, maxD = s.Name, d-1
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance returns the Levenshtein distance between the two strings.
+func editDistance(a, b string) int {
+	prev, curr := make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			c := prev[j-1]
+			if a[i-1] != b[j-1] {
+				c++
+			}
+			if prev[j]+1 < c {
+				c = prev[j] + 1
+			}
+			if curr[j-1]+1 < c {
+				c = curr[j-1] + 1
+			}
+			curr[j] = c
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// parseType is like ParseType, but returns a schema.UnsupportedType for unknown types.
+func parseType(raw string) (schema.Type, error) {
+	// NATIONAL CHAR and NATIONAL VARCHAR are synonyms for NCHAR and NVARCHAR.
+	if strings.HasPrefix(strings.ToLower(raw), "national ") {
+		raw = "n" + strings.TrimSpace(raw[len("national "):])
+	}
 	parts, size, unsigned, err := parseColumn(raw)
 	if err != nil {
 		return nil, err
@@ -143,17 +254,24 @@ func ParseType(raw string) (schema.Type, error) {
 			T:        t,
 			Unsigned: unsigned,
 		}
-		if attr := parts[len(parts)-1]; attr == "zerofill" && size != 0 {
-			ft.Attrs = []schema.Attr{
-				&DisplayWidth{
+		if attr := parts[len(parts)-1]; attr == "zerofill" {
+			if size != 0 {
+				ft.Attrs = append(ft.Attrs, &DisplayWidth{
 					N: size,
-				},
-				&ZeroFill{
-					A: attr,
-				},
+				})
 			}
+			ft.Attrs = append(ft.Attrs, &ZeroFill{
+				A: attr,
+			})
 		}
 		return ft, nil
+	// SERIAL is an alias for BIGINT UNSIGNED NOT NULL AUTO_INCREMENT UNIQUE.
+	// The column attributes are expanded by the HCL converter.
+	case TypeSerial:
+		return &schema.IntegerType{
+			T:        TypeBigInt,
+			Unsigned: true,
+		}, nil
 	case TypeNumeric, TypeDecimal:
 		dt := &schema.DecimalType{
 			T:        t,
@@ -196,6 +314,13 @@ func ParseType(raw string) (schema.Type, error) {
 			T:    t,
 			Size: size,
 		}, nil
+	// The national types are normalized to their CHAR and VARCHAR
+	// forms, and their charset is set on the column level.
+	case TypeNChar, TypeNVarchar:
+		return &schema.StringType{
+			T:    strings.TrimPrefix(t, "n"),
+			Size: size,
+		}, nil
 	case TypeTinyText, TypeMediumText, TypeText, TypeLongText:
 		return &schema.StringType{
 			T: t,
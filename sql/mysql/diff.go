@@ -8,6 +8,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -68,6 +69,20 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if change := d.collationChange(from.Attrs, from.Schema.Attrs, to.Attrs); change != noChange {
 		changes = append(changes, change)
 	}
+	if change := encryptionChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
+	if change := compressionChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
+	if change := unionChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
+	if change := insertMethodChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
+	changes = append(changes, statsChanges(from.Attrs, to.Attrs)...)
+	changes = append(changes, rowsChanges(from.Attrs, to.Attrs)...)
 	if !d.SupportsCheck() && sqlx.Has(to.Attrs, &schema.Check{}) {
 		return nil, fmt.Errorf("version %q does not support CHECK constraints", d.V)
 	}
@@ -90,7 +105,7 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 			checks = append(checks, c)
 		}
 	}
-	return append(changes, checks...), nil
+	return append(changes, equivChecks(checks)...), nil
 }
 
 // ColumnChange returns the schema changes (if any) for migrating one column to the other.
@@ -130,6 +145,9 @@ func (d *diff) ColumnChange(fromT *schema.Table, from, to *schema.Column) (schem
 	if changed {
 		change |= schema.ChangeCollate
 	}
+	if sqlx.Has(from.Attrs, &Compressed{}) != sqlx.Has(to.Attrs, &Compressed{}) {
+		change |= schema.ChangeAttr
+	}
 	return change, nil
 }
 
@@ -331,6 +349,239 @@ func (*diff) autoIncChange(from, to []schema.Attr) schema.Change {
 	return noChange
 }
 
+// encryptionChange returns the schema change for changing the ENCRYPTION
+// option, in case it was defined explicitly in the desired schema.
+func encryptionChange(from, to []schema.Attr) schema.Change {
+	var toE Encryption
+	if !sqlx.Has(to, &toE) {
+		return noChange
+	}
+	if fromE := encryption(from); fromE.V != toE.V {
+		return &schema.ModifyAttr{
+			From: fromE,
+			To:   &toE,
+		}
+	}
+	return noChange
+}
+
+// compressionChange returns the schema change for changing the COMPRESSION
+// option, in case it was defined explicitly in the desired schema.
+func compressionChange(from, to []schema.Attr) schema.Change {
+	var toC Compression
+	if !sqlx.Has(to, &toC) {
+		return noChange
+	}
+	if fromC := compression(from); !strings.EqualFold(fromC.V, toC.V) {
+		return &schema.ModifyAttr{
+			From: fromC,
+			To:   &toC,
+		}
+	}
+	return noChange
+}
+
+// unionChange returns the schema change for changing the UNION
+// option, in case it was defined explicitly in the desired schema.
+func unionChange(from, to []schema.Attr) schema.Change {
+	var toU Union
+	if !sqlx.Has(to, &toU) {
+		return noChange
+	}
+	if fromU := union(from); !reflect.DeepEqual(fromU.T, toU.T) && (len(fromU.T) > 0 || len(toU.T) > 0) {
+		return &schema.ModifyAttr{
+			From: fromU,
+			To:   &toU,
+		}
+	}
+	return noChange
+}
+
+// reUnion matches the UNION option in the inspected CREATE TABLE statement.
+var reUnion = regexp.MustCompile(`(?i)\bUNION\s*=\s*\(([^)]*)\)`)
+
+// union returns the UNION option of the table from its attributes,
+// or from its inspected CREATE TABLE statement.
+func union(attrs []schema.Attr) *Union {
+	var (
+		u Union
+		c CreateStmt
+	)
+	switch {
+	case sqlx.Has(attrs, &u):
+	case sqlx.Has(attrs, &c):
+		if m := reUnion.FindStringSubmatch(c.S); m != nil {
+			for _, n := range strings.Split(m[1], ",") {
+				u.T = append(u.T, strings.Trim(strings.TrimSpace(n), "`"))
+			}
+		}
+	}
+	return &u
+}
+
+// insertMethodChange returns the schema change for changing the INSERT_METHOD
+// option, in case it was defined explicitly in the desired schema.
+func insertMethodChange(from, to []schema.Attr) schema.Change {
+	var toM InsertMethod
+	if !sqlx.Has(to, &toM) {
+		return noChange
+	}
+	if fromM := insertMethod(from); fromM.V != toM.V {
+		return &schema.ModifyAttr{
+			From: fromM,
+			To:   &toM,
+		}
+	}
+	return noChange
+}
+
+// reInsertMethod matches the INSERT_METHOD option in the inspected CREATE TABLE statement.
+var reInsertMethod = regexp.MustCompile(`(?i)\bINSERT_METHOD\s*=\s*(\w+)`)
+
+// insertMethod returns the INSERT_METHOD option of the table from its attributes,
+// or from its inspected CREATE TABLE statement. The default is NO.
+func insertMethod(attrs []schema.Attr) *InsertMethod {
+	var (
+		m InsertMethod
+		c CreateStmt
+	)
+	switch {
+	case sqlx.Has(attrs, &m):
+	case sqlx.Has(attrs, &c) && reInsertMethod.MatchString(c.S):
+		m.V = strings.ToUpper(reInsertMethod.FindStringSubmatch(c.S)[1])
+	default:
+		m.V = "NO"
+	}
+	return &m
+}
+
+// reEncryption matches the ENCRYPTION option in the inspected CREATE_OPTIONS.
+var reEncryption = regexp.MustCompile(`(?i)\bENCRYPTION\s*=\s*['"]?([YN])['"]?`)
+
+// encryption returns the ENCRYPTION option of the table from its
+// attributes, or from its inspected CREATE_OPTIONS. The default is 'N'.
+func encryption(attrs []schema.Attr) *Encryption {
+	var (
+		e  Encryption
+		co CreateOptions
+	)
+	switch {
+	case sqlx.Has(attrs, &e):
+	case sqlx.Has(attrs, &co):
+		if m := reEncryption.FindStringSubmatch(co.V); m != nil {
+			e.V = strings.EqualFold(m[1], "Y")
+		}
+	}
+	return &e
+}
+
+// reCompression matches the COMPRESSION option in the inspected CREATE_OPTIONS.
+var reCompression = regexp.MustCompile(`(?i)\bCOMPRESSION\s*=\s*['"]?(\w+)['"]?`)
+
+// compression returns the COMPRESSION option of the table from its
+// attributes, or from its inspected CREATE_OPTIONS. The default is 'none'.
+func compression(attrs []schema.Attr) *Compression {
+	var (
+		c  Compression
+		co CreateOptions
+	)
+	switch {
+	case sqlx.Has(attrs, &c):
+	case sqlx.Has(attrs, &co) && reCompression.MatchString(co.V):
+		c.V = strings.ToLower(reCompression.FindStringSubmatch(co.V)[1])
+	default:
+		c.V = "none"
+	}
+	return &c
+}
+
+// statsChanges returns the schema changes for the InnoDB statistics
+// options, in case they were defined explicitly in the desired schema.
+func statsChanges(from, to []schema.Attr) []schema.Change {
+	var changes []schema.Change
+	for _, a := range to {
+		name, v, ok := statsOption(a)
+		if !ok {
+			continue
+		}
+		if cur := statsValue(from, name); cur != v {
+			changes = append(changes, &schema.ModifyAttr{
+				From: statsAttr(name, cur),
+				To:   a,
+			})
+		}
+	}
+	return changes
+}
+
+// reStats matches the statistics options in the inspected CREATE_OPTIONS.
+var reStats = map[string]*regexp.Regexp{
+	statsPersistent:  regexp.MustCompile(`(?i)\bstats_persistent\s*=\s*(\w+)`),
+	statsAutoRecalc:  regexp.MustCompile(`(?i)\bstats_auto_recalc\s*=\s*(\w+)`),
+	statsSamplePages: regexp.MustCompile(`(?i)\bstats_sample_pages\s*=\s*(\w+)`),
+}
+
+// statsValue returns the value of the given statistics option from the table
+// attributes, or from its inspected CREATE_OPTIONS. The default is DEFAULT.
+func statsValue(attrs []schema.Attr, name string) string {
+	var co CreateOptions
+	for _, a := range attrs {
+		if n, v, ok := statsOption(a); ok && n == name {
+			return v
+		}
+	}
+	if sqlx.Has(attrs, &co) {
+		if m := reStats[name].FindStringSubmatch(co.V); m != nil {
+			return strings.ToUpper(m[1])
+		}
+	}
+	return "DEFAULT"
+}
+
+// rowsChanges returns the schema changes for the AVG_ROW_LENGTH, MAX_ROWS
+// and MIN_ROWS options, in case they were defined explicitly in the desired schema.
+func rowsChanges(from, to []schema.Attr) []schema.Change {
+	var changes []schema.Change
+	for _, a := range to {
+		name, v, ok := rowsOption(a)
+		if !ok {
+			continue
+		}
+		if cur := rowsValue(from, name); cur != v {
+			changes = append(changes, &schema.ModifyAttr{
+				From: rowsAttr(name, cur),
+				To:   a,
+			})
+		}
+	}
+	return changes
+}
+
+// reRows matches the row options in the inspected CREATE_OPTIONS.
+var reRows = map[string]*regexp.Regexp{
+	avgRowLength: regexp.MustCompile(`(?i)\bavg_row_length\s*=\s*(\d+)`),
+	maxRows:      regexp.MustCompile(`(?i)\bmax_rows\s*=\s*(\d+)`),
+	minRows:      regexp.MustCompile(`(?i)\bmin_rows\s*=\s*(\d+)`),
+}
+
+// rowsValue returns the value of the given rows option from the table
+// attributes, or from its inspected CREATE_OPTIONS. The default is 0.
+func rowsValue(attrs []schema.Attr, name string) uint64 {
+	var co CreateOptions
+	for _, a := range attrs {
+		if n, v, ok := rowsOption(a); ok && n == name {
+			return v
+		}
+	}
+	if sqlx.Has(attrs, &co) {
+		if m := reRows[name].FindStringSubmatch(co.V); m != nil {
+			v, _ := strconv.ParseUint(m[1], 10, 64)
+			return v
+		}
+	}
+	return 0
+}
+
 // indexType returns the index type from its attribute.
 // The default type is BTREE if no type was specified.
 func indexType(attr []schema.Attr) *IndexType {
@@ -350,6 +601,180 @@ func enforced(attr []schema.Attr) bool {
 	return true
 }
 
+// equivChecks removes pairs of dropped and added CHECK constraints that are
+// equivalent. i.e. an inspected constraint that was named by the database, and
+// an unnamed constraint with the same normalized expression and enforcement.
+func equivChecks(changes []schema.Change) []schema.Change {
+	skip := make(map[schema.Change]bool)
+	for _, c1 := range changes {
+		drop, ok := c1.(*schema.DropCheck)
+		if !ok {
+			continue
+		}
+		for _, c2 := range changes {
+			add, ok := c2.(*schema.AddCheck)
+			if ok && !skip[c2] && add.C.Name == "" && enforced(add.C.Attrs) == enforced(drop.C.Attrs) &&
+				NormalizeCheckExpr(add.C.Expr) == NormalizeCheckExpr(drop.C.Expr) {
+				skip[c1], skip[c2] = true, true
+				break
+			}
+		}
+	}
+	if len(skip) == 0 {
+		return changes
+	}
+	kept := make([]schema.Change, 0, len(changes)-len(skip))
+	for _, c := range changes {
+		if !skip[c] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// NormalizeCheckExpr returns a canonical form of the given CHECK expression, that
+// can be used to compare expressions written by users with the ones returned by
+// the database. For example, "price > 0" and "(`price` > 0)" are normalized to
+// the same form. Identifiers are unquoted, tokens other than string literals are
+// lowercased, charset introducers are removed, and redundant parentheses are
+// trimmed. Note, the result is meant for comparison and not for execution.
+func NormalizeCheckExpr(x string) string {
+	return strings.Join(trimParens(checkTokens(x)), " ")
+}
+
+// checkOps maps operators to their canonical form.
+var checkOps = map[string]string{"!=": "<>", "&&": "and", "||": "or"}
+
+// checkTokens splits the given expression into tokens.
+func checkTokens(x string) []string {
+	var (
+		toks []string
+		// Position where the last word token ended.
+		wordEnd = -1
+	)
+	for i := 0; i < len(x); {
+		switch c := x[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			j := i + 1
+			for ; j < len(x) && x[j] != c; j++ {
+				if x[j] == '\\' {
+					j++
+				}
+			}
+			if j++; j > len(x) {
+				j = len(x)
+			}
+			// Drop the charset introducer (e.g. _utf8mb4) MySQL adds to string literals.
+			if n := len(toks); n > 0 && wordEnd == i && strings.HasPrefix(toks[n-1], "_") {
+				toks = toks[:n-1]
+			}
+			toks = append(toks, x[i:j])
+			i = j
+		case c == '`':
+			j := strings.IndexByte(x[i+1:], '`')
+			if j == -1 {
+				j = len(x) - i - 1
+			}
+			toks = append(toks, strings.ToLower(x[i+1:i+1+j]))
+			i += j + 2
+		case c == '(' || c == ')' || c == ',':
+			toks = append(toks, string(c))
+			i++
+		case isWordByte(c):
+			j := i + 1
+			for j < len(x) && isWordByte(x[j]) {
+				j++
+			}
+			toks = append(toks, strings.ToLower(x[i:j]))
+			i, wordEnd = j, j
+		default:
+			op := string(c)
+			for _, o := range []string{"<=>", "<=", ">=", "<>", "!=", "&&", "||", "<<", ">>", ":="} {
+				if strings.HasPrefix(x[i:], o) {
+					op = o
+					break
+				}
+			}
+			if o, ok := checkOps[op]; ok {
+				op = o
+			}
+			toks = append(toks, op)
+			i += len(op)
+		}
+	}
+	return toks
+}
+
+// isWordByte reports if c can be part of a word (e.g. identifier, keyword or number).
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// trimParens removes redundant parentheses from the given tokens. Parentheses
+// are redundant if they wrap the entire expression or another parenthesized
+// group, if they wrap a single token, or if they wrap an operand of a logical
+// operator that does not contain logical operators itself.
+func trimParens(toks []string) []string {
+	for i := 0; i < len(toks); i++ {
+		if toks[i] != "(" {
+			continue
+		}
+		j, logical := closeParen(toks, i)
+		if j == -1 {
+			return toks
+		}
+		prev, next := "", ""
+		if i > 0 {
+			prev = toks[i-1]
+		}
+		if j < len(toks)-1 {
+			next = toks[j+1]
+		}
+		switch {
+		case j == i+1, logical == ",":
+		case (prev == "" || prev == "(") && (next == "" || next == ")"),
+			logical == "" && isLogicalBound(prev, "(") && isLogicalBound(next, ")"),
+			// A single token that is not a function or an IN list argument.
+			j == i+2 && (prev == "" || !isWordByte(prev[0])):
+			toks = append(toks[:i:i], append(toks[i+1:j], toks[j+1:]...)...)
+			// Start over, as the enclosing group may be redundant now.
+			i = -1
+		}
+	}
+	return toks
+}
+
+// closeParen returns the index of the parenthesis closing the one at index i,
+// and the last logical operator or comma found at its top level, if any.
+func closeParen(toks []string, i int) (int, string) {
+	var logical string
+	for j, depth := i+1, 0; j < len(toks); j++ {
+		switch t := toks[j]; {
+		case t == "(":
+			depth++
+		case t == ")" && depth == 0:
+			return j, logical
+		case t == ")":
+			depth--
+		case depth == 0 && logical != "," && (t == "," || isLogicalBound(t, "")):
+			logical = t
+		}
+	}
+	return -1, logical
+}
+
+// isLogicalBound reports if the token t is a logical operator, or
+// the empty string (start or end of the expression), or the given paren.
+func isLogicalBound(t, paren string) bool {
+	switch t {
+	case "", paren, ",", "and", "or", "xor":
+		return true
+	}
+	return false
+}
+
 // noChange describes a zero change.
 var noChange struct{ schema.Change }
 
@@ -374,6 +799,10 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 			return false, err
 		}
 		changed = ft != tt
+		if _, ok := fromT.(*schema.SpatialType); ok && !changed {
+			var s1, s2 SRID
+			changed = sqlx.Has(from.Attrs, &s1) != sqlx.Has(to.Attrs, &s2) || s1.ID != s2.ID
+		}
 	case *schema.EnumType:
 		toT := toT.(*schema.EnumType)
 		changed = !sqlx.ValuesEqual(fromT.Values, toT.Values)
@@ -392,7 +821,8 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 			}
 			fromT.T, toT.T = ft[0], tt[0]
 		}
-		changed = fromT.T != toT.T || fromT.Unsigned != toT.Unsigned
+		changed = fromT.T != toT.T || fromT.Unsigned != toT.Unsigned ||
+			sqlx.Has(fromT.Attrs, &ZeroFill{}) != sqlx.Has(toT.Attrs, &ZeroFill{})
 	case *SetType:
 		toT := toT.(*SetType)
 		changed = !sqlx.ValuesEqual(fromT.Values, toT.Values)
@@ -406,12 +836,22 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
 	d1, ok1 := sqlx.DefaultValue(from)
 	d2, ok2 := sqlx.DefaultValue(to)
+	// An explicit DEFAULT NULL is equivalent to
+	// no default value, as reported by inspection.
+	if nullDefault(from) || nullDefault(to) {
+		return ok1 && !nullDefault(from) || ok2 && !nullDefault(to), nil
+	}
 	if ok1 != ok2 {
 		return true, nil
 	}
 	if d1 == d2 {
 		return false, nil
 	}
+	// Expression defaults that were inspected from the database
+	// may contain charset introducers, e.g. (_utf8mb4'{}').
+	if x1, x2 := trimIntroducers(d1), trimIntroducers(d2); x1 == x2 {
+		return false, nil
+	}
 	switch from.Type.Type.(type) {
 	case *schema.BinaryType:
 		a, err1 := binValue(d1)
@@ -512,12 +952,24 @@ func equalsStringValues(x1, x2 string) bool {
 	return a == b && err1 == nil && err2 == nil
 }
 
+// reIntroducer matches charset introducers of string literals in expressions.
+var reIntroducer = regexp.MustCompile(`(?i)([(\s,])_[a-z0-9]+'`)
+
+// trimIntroducers removes the charset introducers from parenthesized expressions,
+// e.g. "(_utf8mb4'{}')" is converted to "('{}')".
+func trimIntroducers(x string) string {
+	if !strings.HasPrefix(x, "(") || !strings.HasSuffix(x, ")") {
+		return x
+	}
+	return reIntroducer.ReplaceAllString(x, "$1'")
+}
+
 // boolValue returns the MySQL boolean value from the given string (if it is known).
 func boolValue(x string) (bool, error) {
-	switch x {
-	case "1", "'1'", "TRUE", "true":
+	switch strings.ToLower(x) {
+	case "1", "'1'", "true":
 		return true, nil
-	case "0", "'0'", "FALSE", "false":
+	case "0", "'0'", "false":
 		return false, nil
 	default:
 		return false, fmt.Errorf("mysql: unknown value: %q", x)
@@ -557,6 +1009,12 @@ search:
 	return false
 }
 
+// nullDefault reports if the column was defined with an explicit DEFAULT NULL.
+func nullDefault(c *schema.Column) bool {
+	x, ok := c.Default.(*schema.RawExpr)
+	return ok && strings.EqualFold(x.X, "NULL")
+}
+
 // defaultCollate appends the default COLLATE to the attributes in case a
 // custom character-set was defined for the element and the COLLATE was not.
 func (d *diff) defaultCollate(attrs *[]schema.Attr) error {
@@ -598,3 +1056,141 @@ func (d *diff) defaultCharset(attrs *[]schema.Attr) error {
 	schema.ReplaceOrAppend(attrs, &schema.Charset{V: v})
 	return nil
 }
+
+// DestructiveChanges returns the changes that may cause data loss when applied to the
+// database. For example, dropping a schema, a table or a column, or narrowing the type
+// of a column (e.g. from varchar(255) to varchar(100)). Changes of modified tables and
+// schemas are inspected recursively, and the destructive ones are returned as-is.
+func DestructiveChanges(changes []schema.Change) []schema.Change {
+	var destructive []schema.Change
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.DropSchema, *schema.DropTable, *schema.DropColumn:
+			destructive = append(destructive, c)
+		case *schema.ModifySchema:
+			destructive = append(destructive, DestructiveChanges(c.Changes)...)
+		case *schema.ModifyTable:
+			destructive = append(destructive, DestructiveChanges(c.Changes)...)
+		case *schema.ModifyColumn:
+			if c.Change.Is(schema.ChangeType) && narrowType(c.From.Type.Type, c.To.Type.Type) {
+				destructive = append(destructive, c)
+			}
+		}
+	}
+	return destructive
+}
+
+// typeRanks holds the storage ranks of MySQL types from the same family.
+var typeRanks = map[string]int{
+	TypeTinyInt:    1,
+	TypeSmallInt:   2,
+	TypeMediumInt:  3,
+	TypeInt:        4,
+	TypeBigInt:     5,
+	TypeTinyText:   1,
+	TypeText:       2,
+	TypeMediumText: 3,
+	TypeLongText:   4,
+	TypeTinyBlob:   1,
+	TypeBlob:       2,
+	TypeMediumBlob: 3,
+	TypeLongBlob:   4,
+}
+
+// timeWidens holds the MySQL temporal types that can hold all values of other
+// temporal types. DATETIME covers the range of DATE and TIMESTAMP, while the
+// range of TIMESTAMP (1970-2038) does not cover DATE, and YEAR and TIME are
+// not convertible to others.
+var timeWidens = map[string][]string{
+	TypeDate:      {TypeDateTime},
+	TypeTimestamp: {TypeDateTime},
+}
+
+// timePrecision returns the fractional seconds precision of the type.
+func timePrecision(t *schema.TimeType) int {
+	if t.Precision == nil {
+		return 0
+	}
+	return *t.Precision
+}
+
+// narrowType reports if converting a column from one type to the
+// other may truncate or reject its existing values.
+func narrowType(from, to schema.Type) bool {
+	if reflect.TypeOf(from) != reflect.TypeOf(to) {
+		return true
+	}
+	switch from := from.(type) {
+	case *schema.IntegerType:
+		to := to.(*schema.IntegerType)
+		r1, r2 := typeRanks[strings.ToLower(from.T)], typeRanks[strings.ToLower(to.T)]
+		switch {
+		case from.Unsigned == to.Unsigned:
+			return r2 < r1
+		case from.Unsigned:
+			// Signed types can hold the unsigned values of lower ranks.
+			return r2 <= r1
+		default:
+			return true
+		}
+	case *schema.StringType:
+		to := to.(*schema.StringType)
+		r1, ok1 := typeRanks[strings.ToLower(from.T)]
+		r2, ok2 := typeRanks[strings.ToLower(to.T)]
+		switch {
+		case ok1 && ok2:
+			return r2 < r1
+		case ok1 != ok2:
+			// Changing from TEXT to VARCHAR, or vice versa.
+			return ok1 || to.Size != 0 && to.Size < 65535
+		default:
+			return to.Size < from.Size
+		}
+	case *schema.BinaryType:
+		to := to.(*schema.BinaryType)
+		r1, ok1 := typeRanks[strings.ToLower(from.T)]
+		r2, ok2 := typeRanks[strings.ToLower(to.T)]
+		switch {
+		case ok1 && ok2:
+			return r2 < r1
+		case ok1 != ok2:
+			return ok1 || to.Size != nil && *to.Size < 65535
+		default:
+			return to.Size != nil && (from.Size == nil || *to.Size < *from.Size)
+		}
+	case *schema.DecimalType:
+		to := to.(*schema.DecimalType)
+		return from.Unsigned != to.Unsigned && !from.Unsigned ||
+			to.Scale < from.Scale || to.Precision-to.Scale < from.Precision-from.Scale
+	case *schema.FloatType:
+		to := to.(*schema.FloatType)
+		return strings.EqualFold(from.T, TypeDouble) && strings.EqualFold(to.T, TypeFloat) ||
+			from.Unsigned != to.Unsigned && !from.Unsigned
+	case *schema.TimeType:
+		to := to.(*schema.TimeType)
+		if !strings.EqualFold(from.T, to.T) && !containsAll(timeWidens[strings.ToLower(from.T)], []string{strings.ToLower(to.T)}) {
+			return true
+		}
+		return timePrecision(to) < timePrecision(from)
+	case *schema.EnumType:
+		return !containsAll(to.(*schema.EnumType).Values, from.Values)
+	case *SetType:
+		return !containsAll(to.(*SetType).Values, from.Values)
+	default:
+		return false
+	}
+}
+
+// containsAll reports if all values in vs exist in s.
+func containsAll(s, vs []string) bool {
+	set := make(map[string]bool, len(s))
+	for _, v := range s {
+		set[v] = true
+	}
+	for _, v := range vs {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
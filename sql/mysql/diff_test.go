@@ -138,6 +138,11 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "equivalent checks",
+			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&schema.Check{Name: "t1_chk_1", Expr: "((`a` > 0) and (`b` in (_utf8mb4'x',_utf8mb4'y')))"}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Expr: "a > 0 AND b IN ('x', 'y')"}}},
+		},
 		{
 			name: "modify check",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&schema.Check{Name: "users_chk1_c1", Expr: "(`c1` <>_latin1\\'foo\\')", Attrs: []schema.Attr{&Enforced{V: false}}}}},
@@ -491,6 +496,29 @@ func TestDiff_TableDiff(t *testing.T) {
 	}
 }
 
+func TestNormalizeCheckExpr(t *testing.T) {
+	for _, tt := range []struct{ x1, x2 string }{
+		{"price > 0", "(`price` > 0)"},
+		{"price>0", "(`Price` > 0)"},
+		{"a > 0 AND b < 10", "((`a` > 0) and (`b` < 10))"},
+		{"a > 0 && (b < 10 || c = 1)", "((`a` > 0) and ((`b` < 10) or (`c` = 1)))"},
+		{"status IN ('a', 'b')", "(`status` in (_utf8mb4'a',_utf8mb4'b'))"},
+		{"a != b", "(`a` <> `b`)"},
+		{"JSON_VALID(doc)", "json_valid(`doc`)"},
+		{"x > -1", "(`x` > -(1))"},
+	} {
+		require.Equal(t, NormalizeCheckExpr(tt.x1), NormalizeCheckExpr(tt.x2), "%s = %s", tt.x1, tt.x2)
+	}
+	for _, tt := range []struct{ x1, x2 string }{
+		{"(a or b) and c", "a or b and c"},
+		{"(a + b) * c", "a + b * c"},
+		{"name = 'A'", "name = 'a'"},
+		{"a in ((1, 2))", "a in (1, 2)"},
+	} {
+		require.NotEqual(t, NormalizeCheckExpr(tt.x1), NormalizeCheckExpr(tt.x2), "%s != %s", tt.x1, tt.x2)
+	}
+}
+
 func TestDiff_UnsupportedChecks(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -636,3 +664,87 @@ func TestDefaultDiff(t *testing.T) {
 	require.Len(t, changes, 1)
 	require.IsType(t, &schema.DropTable{}, changes[0])
 }
+
+func TestDestructiveChanges(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			AddColumns(
+				schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)),
+				schema.NewIntColumn("age", TypeInt),
+			)
+		modify = func(from, to schema.Type) *schema.ModifyColumn {
+			return &schema.ModifyColumn{
+				From:   &schema.Column{Name: "c", Type: &schema.ColumnType{Type: from}},
+				To:     &schema.Column{Name: "c", Type: &schema.ColumnType{Type: to}},
+				Change: schema.ChangeType,
+			}
+		}
+		narrowVarchar = modify(&schema.StringType{T: TypeVarchar, Size: 255}, &schema.StringType{T: TypeVarchar, Size: 100})
+		widenVarchar  = modify(&schema.StringType{T: TypeVarchar, Size: 100}, &schema.StringType{T: TypeVarchar, Size: 255})
+		narrowInt     = modify(&schema.IntegerType{T: TypeBigInt}, &schema.IntegerType{T: TypeInt})
+		widenInt      = modify(&schema.IntegerType{T: TypeInt}, &schema.IntegerType{T: TypeBigInt})
+		unsignedInt   = modify(&schema.IntegerType{T: TypeInt}, &schema.IntegerType{T: TypeInt, Unsigned: true})
+		textToVarchar = modify(&schema.StringType{T: TypeText}, &schema.StringType{T: TypeVarchar, Size: 255})
+		widenText     = modify(&schema.StringType{T: TypeText}, &schema.StringType{T: TypeLongText})
+		intToVarchar  = modify(&schema.IntegerType{T: TypeInt}, &schema.StringType{T: TypeVarchar, Size: 255})
+		dropEnum      = modify(&schema.EnumType{T: TypeEnum, Values: []string{"a", "b"}}, &schema.EnumType{T: TypeEnum, Values: []string{"a"}})
+		addEnum       = modify(&schema.EnumType{T: TypeEnum, Values: []string{"a"}}, &schema.EnumType{T: TypeEnum, Values: []string{"a", "b"}})
+		narrowDecimal = modify(&schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}, &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 1})
+		p3            = 3
+		widenTS       = modify(&schema.TimeType{T: TypeTimestamp}, &schema.TimeType{T: TypeTimestamp, Precision: &p3})
+		narrowTS      = modify(&schema.TimeType{T: TypeTimestamp, Precision: &p3}, &schema.TimeType{T: TypeTimestamp})
+		dateToDT      = modify(&schema.TimeType{T: TypeDate}, &schema.TimeType{T: TypeDateTime})
+		dtToDate      = modify(&schema.TimeType{T: TypeDateTime}, &schema.TimeType{T: TypeDate})
+		tsToDT        = modify(&schema.TimeType{T: TypeTimestamp, Precision: &p3}, &schema.TimeType{T: TypeDateTime, Precision: &p3})
+		dtToTS        = modify(&schema.TimeType{T: TypeDateTime}, &schema.TimeType{T: TypeTimestamp})
+		timeToDT      = modify(&schema.TimeType{T: TypeTime}, &schema.TimeType{T: TypeDateTime})
+		nullChange    = &schema.ModifyColumn{From: users.Columns[0], To: users.Columns[0], Change: schema.ChangeNull}
+		dropColumn    = &schema.DropColumn{C: users.Columns[1]}
+		dropTable     = &schema.DropTable{T: users}
+		dropSchema    = &schema.DropSchema{S: schema.New("test")}
+	)
+	changes := []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddColumn{C: schema.NewIntColumn("id", TypeInt)},
+				&schema.AddIndex{I: schema.NewIndex("name").AddColumns(users.Columns[0])},
+				narrowVarchar, widenVarchar, narrowInt, widenInt, unsignedInt, textToVarchar,
+				widenText, intToVarchar, dropEnum, addEnum, narrowDecimal, widenTS, narrowTS,
+				dateToDT, dtToDate, tsToDT, dtToTS, timeToDT, nullChange, dropColumn,
+			},
+		},
+		dropTable,
+		&schema.ModifySchema{S: schema.New("test"), Changes: []schema.Change{&schema.ModifyAttr{From: &schema.Charset{V: "latin1"}, To: &schema.Charset{V: "utf8mb4"}}}},
+		dropSchema,
+	}
+	require.Equal(t, []schema.Change{
+		narrowVarchar, narrowInt, unsignedInt, textToVarchar, intToVarchar,
+		dropEnum, narrowDecimal, narrowTS, dtToDate, dtToTS, timeToDT, dropColumn, dropTable, dropSchema,
+	}, DestructiveChanges(changes))
+	require.Empty(t, DestructiveChanges([]schema.Change{widenVarchar, widenInt, widenText, addEnum, widenTS, dateToDT, tsToDT}))
+}
+
+func TestNarrowType(t *testing.T) {
+	for _, tt := range []struct {
+		from, to schema.Type
+		narrow   bool
+	}{
+		{from: &schema.IntegerType{T: TypeInt, Unsigned: true}, to: &schema.IntegerType{T: TypeBigInt}},
+		{from: &schema.IntegerType{T: TypeTinyInt, Unsigned: true}, to: &schema.IntegerType{T: TypeSmallInt}},
+		{from: &schema.IntegerType{T: TypeInt, Unsigned: true}, to: &schema.IntegerType{T: TypeInt}, narrow: true},
+		{from: &schema.IntegerType{T: TypeBigInt, Unsigned: true}, to: &schema.IntegerType{T: TypeInt}, narrow: true},
+		{from: &schema.IntegerType{T: TypeInt}, to: &schema.IntegerType{T: TypeBigInt, Unsigned: true}, narrow: true},
+		{from: &schema.IntegerType{T: TypeInt, Unsigned: true}, to: &schema.IntegerType{T: TypeBigInt, Unsigned: true}},
+		{from: &schema.TimeType{T: TypeDate}, to: &schema.TimeType{T: TypeDateTime}},
+		{from: &schema.TimeType{T: TypeTimestamp}, to: &schema.TimeType{T: TypeDateTime}},
+		{from: &schema.TimeType{T: TypeDate}, to: &schema.TimeType{T: TypeTimestamp}, narrow: true},
+		{from: &schema.TimeType{T: TypeDateTime}, to: &schema.TimeType{T: TypeTimestamp}, narrow: true},
+		{from: &schema.TimeType{T: TypeYear}, to: &schema.TimeType{T: TypeDate}, narrow: true},
+		{from: &schema.TimeType{T: TypeYear}, to: &schema.TimeType{T: TypeDateTime}, narrow: true},
+		{from: &schema.TimeType{T: TypeTime}, to: &schema.TimeType{T: TypeDateTime}, narrow: true},
+	} {
+		require.Equal(t, tt.narrow, narrowType(tt.from, tt.to), "%v -> %v", tt.from, tt.to)
+	}
+}
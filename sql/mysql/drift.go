@@ -0,0 +1,100 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import "ariga.io/atlas/sql/schema"
+
+// Drift evaluates the given Atlas HCL document, and returns the changes needed for
+// migrating the current schema (e.g. inspected from a live database) to the desired
+// state defined in the document. No changes mean the current schema matches the
+// document. Note, the document must contain a single schema named as the current one.
+//
+// Filters can be provided to compute only some categories of changes. For example,
+// Drift(data, current, OnlyColumns) returns only the column changes of the tables.
+func Drift(data []byte, current *schema.Schema, filters ...ChangeFilter) ([]schema.Change, error) {
+	var desired schema.Schema
+	if err := EvalHCLBytes(data, &desired, nil); err != nil {
+		return nil, err
+	}
+	changes, err := DefaultDiff.SchemaDiff(current, &desired)
+	if err != nil || len(filters) == 0 {
+		return changes, err
+	}
+	return FilterChanges(changes, filters...), nil
+}
+
+// A ChangeFilter reports if a change should be kept by FilterChanges.
+type ChangeFilter func(schema.Change) bool
+
+// Filters for the common categories of changes.
+var (
+	// OnlyColumns keeps only the changes of columns.
+	OnlyColumns ChangeFilter = func(c schema.Change) bool {
+		switch c.(type) {
+		case *schema.AddColumn, *schema.DropColumn, *schema.ModifyColumn, *schema.RenameColumn:
+			return true
+		}
+		return false
+	}
+	// OnlyIndexes keeps only the changes of indexes.
+	OnlyIndexes ChangeFilter = func(c schema.Change) bool {
+		switch c.(type) {
+		case *schema.AddIndex, *schema.DropIndex, *schema.ModifyIndex, *schema.RenameIndex:
+			return true
+		}
+		return false
+	}
+	// OnlyForeignKeys keeps only the changes of foreign keys.
+	OnlyForeignKeys ChangeFilter = func(c schema.Change) bool {
+		switch c.(type) {
+		case *schema.AddForeignKey, *schema.DropForeignKey, *schema.ModifyForeignKey:
+			return true
+		}
+		return false
+	}
+	// OnlyChecks keeps only the changes of CHECK constraints.
+	OnlyChecks ChangeFilter = func(c schema.Change) bool {
+		switch c.(type) {
+		case *schema.AddCheck, *schema.DropCheck, *schema.ModifyCheck:
+			return true
+		}
+		return false
+	}
+)
+
+// FilterChanges returns the changes that are accepted by at least one of the given
+// filters. The filters are applied to the changes of modified tables, and tables that
+// are left without changes are removed. Other changes (e.g. adding or dropping tables)
+// are kept only if they are accepted by one of the filters.
+func FilterChanges(changes []schema.Change, filters ...ChangeFilter) []schema.Change {
+	keep := func(c schema.Change) bool {
+		for _, f := range filters {
+			if f(c) {
+				return true
+			}
+		}
+		return false
+	}
+	var filtered []schema.Change
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok {
+			if keep(c) {
+				filtered = append(filtered, c)
+			}
+			continue
+		}
+		var tc []schema.Change
+		for _, c := range m.Changes {
+			if keep(c) {
+				tc = append(tc, c)
+			}
+		}
+		if len(tc) > 0 {
+			filtered = append(filtered, &schema.ModifyTable{T: m.T, Changes: tc})
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,125 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrift(t *testing.T) {
+	const f = `
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type = bigint
+  }
+  column "name" {
+    type = varchar(255)
+    null = true
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "name" {
+    columns = [column.name]
+  }
+}
+`
+	users := schema.NewTable("users").
+		AddColumns(
+			schema.NewIntColumn("id", TypeBigInt),
+			schema.NewNullStringColumn("name", TypeVarchar, schema.StringSize(255)),
+		)
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	users.AddIndexes(schema.NewIndex("name").AddColumns(users.Columns[1]))
+	current := schema.New("test").AddTables(users)
+	changes, err := Drift([]byte(f), current)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Drifted schema.
+	users.Columns[1].Type.Null = false
+	current.AddTables(schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", TypeInt)))
+	changes, err = Drift([]byte(f), current)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	modify, ok := changes[0].(*schema.ModifyTable)
+	require.True(t, ok)
+	require.Equal(t, "users", modify.T.Name)
+	require.Len(t, modify.Changes, 1)
+	require.True(t, modify.Changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeNull))
+	drop, ok := changes[1].(*schema.DropTable)
+	require.True(t, ok)
+	require.Equal(t, "posts", drop.T.Name)
+
+	_, err = Drift([]byte(f), schema.New("other"))
+	require.Error(t, err)
+}
+
+func TestDrift_Filters(t *testing.T) {
+	const f = `
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type = bigint
+  }
+  column "name" {
+    type = varchar(255)
+    null = true
+  }
+  index "name" {
+    columns = [column.name]
+  }
+}
+`
+	users := schema.NewTable("users").
+		AddColumns(
+			schema.NewIntColumn("id", TypeBigInt),
+			schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)),
+		)
+	current := schema.New("test").AddTables(users, schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", TypeInt)))
+	changes, err := Drift([]byte(f), current)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Len(t, changes[0].(*schema.ModifyTable).Changes, 2)
+
+	changes, err = Drift([]byte(f), current, OnlyColumns)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	modify := changes[0].(*schema.ModifyTable)
+	require.Equal(t, "users", modify.T.Name)
+	require.Len(t, modify.Changes, 1)
+	require.True(t, modify.Changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeNull))
+
+	changes, err = Drift([]byte(f), current, OnlyIndexes)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	modify = changes[0].(*schema.ModifyTable)
+	require.Len(t, modify.Changes, 1)
+	require.Equal(t, "name", modify.Changes[0].(*schema.AddIndex).I.Name)
+
+	changes, err = Drift([]byte(f), current, OnlyForeignKeys)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Filters are combined, and can be used on any list of changes.
+	changes, err = Drift([]byte(f), current)
+	require.NoError(t, err)
+	filtered := FilterChanges(changes, OnlyIndexes, func(c schema.Change) bool {
+		_, ok := c.(*schema.DropTable)
+		return ok
+	})
+	require.Len(t, filtered, 2)
+	require.IsType(t, &schema.AddIndex{}, filtered[0].(*schema.ModifyTable).Changes[0])
+	require.Equal(t, "posts", filtered[1].(*schema.DropTable).T.Name)
+	// The original changes are not modified.
+	require.Len(t, changes[0].(*schema.ModifyTable).Changes, 2)
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -35,6 +36,9 @@ type (
 		mysqlversion.V
 		collate string
 		charset string
+		// The version comment of the server, e.g.
+		// "Percona Server (GPL), Release 19, ...".
+		comment string
 	}
 )
 
@@ -58,7 +62,7 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 	if err != nil {
 		return nil, fmt.Errorf("mysql: query system variables: %w", err)
 	}
-	if err := sqlx.ScanOne(rows, &c.V, &c.collate, &c.charset); err != nil {
+	if err := sqlx.ScanOne(rows, &c.V, &c.collate, &c.charset, &c.comment); err != nil {
 		return nil, fmt.Errorf("mysql: scan system variables: %w", err)
 	}
 	if c.TiDB() {
@@ -77,6 +81,31 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 	}, nil
 }
 
+var _ migrate.StmtScanner = (*Driver)(nil)
+
+// ScanStmts implements migrate.StmtScanner. In MySQL, the hash character
+// (#) starts a single-line comment, like the double-dash sequence, and
+// executable comments (e.g. "/*!50003 ... */") are executed as statements.
+func (d *Driver) ScanStmts(input string) ([]*migrate.Stmt, error) {
+	return migrate.Stmts(input, migrate.WithHashComments(true), migrate.WithExecutableComments(true))
+}
+
+// rePercona matches the version comment of Percona Server for MySQL and Percona
+// XtraDB Cluster, e.g. "Percona Server (GPL), Release 19, Revision 31e88966cd3".
+var rePercona = regexp.MustCompile(`^Percona (Server|XtraDB Cluster)\b`)
+
+// Percona reports if the server is Percona Server for MySQL.
+func (c conn) Percona() bool {
+	return rePercona.MatchString(c.comment)
+}
+
+// SupportsCompressedColumns reports if the server supports compressed
+// columns. i.e. "COMPRESSED" in MariaDB, and "COLUMN_FORMAT COMPRESSED"
+// in Percona Server.
+func (c conn) SupportsCompressedColumns() bool {
+	return c.Maria() || c.Percona()
+}
+
 func (d *Driver) dev() *sqlx.DevDriver {
 	return &sqlx.DevDriver{Driver: d, MaxNameLen: 64}
 }
@@ -293,6 +322,7 @@ const (
 	TypeSmallInt  = "smallint"  // MYSQL_TYPE_SHORT
 	TypeMediumInt = "mediumint" // MYSQL_TYPE_INT24
 	TypeBigInt    = "bigint"    // MYSQL_TYPE_LONGLONG
+	TypeSerial    = "serial"    // BIGINT UNSIGNED NOT NULL AUTO_INCREMENT UNIQUE
 
 	TypeDecimal = "decimal" // MYSQL_TYPE_DECIMAL
 	TypeNumeric = "numeric" // MYSQL_TYPE_DECIMAL (numeric_type rule in sql_yacc.yy)
@@ -308,6 +338,8 @@ const (
 
 	TypeVarchar    = "varchar"    // MYSQL_TYPE_VAR_STRING, MYSQL_TYPE_VARCHAR
 	TypeChar       = "char"       // MYSQL_TYPE_STRING
+	TypeNVarchar   = "nvarchar"   // VARCHAR + CHARACTER_SET utf8mb3 (national_char_type in sql_yacc.yy)
+	TypeNChar      = "nchar"      // CHAR + CHARACTER_SET utf8mb3 (national_char_type in sql_yacc.yy)
 	TypeVarBinary  = "varbinary"  // MYSQL_TYPE_VAR_STRING + NULL CHARACTER_SET.
 	TypeBinary     = "binary"     // MYSQL_TYPE_STRING + NULL CHARACTER_SET.
 	TypeBlob       = "blob"       // MYSQL_TYPE_BLOB
@@ -183,6 +183,29 @@ func TestDriver_Version(t *testing.T) {
 	require.Equal(t, "8.0.13", drv.(vr).Version())
 }
 
+func TestDriver_Percona(t *testing.T) {
+	for _, tt := range []struct {
+		comment string
+		percona bool
+	}{
+		{comment: "Percona Server (GPL), Release 19, Revision 31e88966cd3", percona: true},
+		{comment: "Percona Server for MySQL (GPL), Release 2, Revision d5d7f7b4", percona: true},
+		{comment: "Percona XtraDB Cluster (GPL), Release rel15, Revision 8b2b2a2", percona: true},
+		{comment: "Source distribution"},
+		{comment: "MySQL Community Server - GPL"},
+		{comment: "Built by ACME, not Percona Server"},
+	} {
+		t.Run(tt.comment, func(t *testing.T) {
+			db, m, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{m}.versionComment("8.0.19", tt.comment)
+			drv, err := Open(db)
+			require.NoError(t, err)
+			require.Equal(t, tt.percona, drv.(*Driver).Percona())
+		})
+	}
+}
+
 type mockInspector struct {
 	schema.Inspector
 	realm  *schema.Realm
@@ -209,3 +232,33 @@ func (m *mockOpener) Conn(ctx context.Context) (*sql.Conn, error) {
 	m.opened++
 	return m.DB.Conn(ctx)
 }
+
+func TestDriver_ScanStmts(t *testing.T) {
+	f := migrate.NewLocalFile("1.sql", []byte("# Create the table.\nCREATE TABLE t (c int);\nSELECT c # 2\nFROM t;\n"))
+	stmts, err := migrate.FileStmts(&Driver{}, f)
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE t (c int);", "SELECT c # 2\nFROM t;"}, stmts)
+}
+
+func TestReservedNames(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("orders").
+				AddColumns(
+					schema.NewIntColumn("id", TypeInt),
+					schema.NewIntColumn("order", TypeInt),
+					schema.NewStringColumn("select", TypeVarchar, schema.StringSize(10)),
+				),
+			schema.NewTable("Table").
+				AddColumns(schema.NewIntColumn("name", TypeInt)),
+		)
+	s.Tables[0].AddIndexes(schema.NewIndex("key").AddColumns(s.Tables[0].Columns[0]))
+	require.Equal(t, []string{
+		`column "orders"."order"`,
+		`column "orders"."select"`,
+		`index "orders"."key"`,
+		`table "Table"`,
+	}, ReservedNames(s))
+	require.True(t, IsReserved("SELECT"))
+	require.False(t, IsReserved("users"))
+}
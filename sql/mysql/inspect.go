@@ -78,6 +78,9 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 		if err := i.columns(ctx, s); err != nil {
 			return err
 		}
+		if err := i.srids(ctx, s); err != nil {
+			return err
+		}
 		if err := i.indexes(ctx, s); err != nil {
 			return err
 		}
@@ -241,11 +244,24 @@ func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) error {
 			Null: nullable.String == "YES",
 		},
 	}
-	ct, err := ParseType(c.Type.Raw)
+	// MariaDB reports compressed columns in their
+	// type. e.g. "blob /*M!100301 COMPRESSED*/".
+	raw := c.Type.Raw
+	if i.Maria() && reCompressed.MatchString(raw) {
+		raw = reCompressed.ReplaceAllString(raw, "")
+		c.Attrs = append(c.Attrs, &Compressed{})
+	}
+	ct, err := parseType(raw)
 	if err != nil {
 		return err
 	}
 	c.Type.Type = ct
+	// Percona Server does not report the column format in
+	// INFORMATION_SCHEMA, and it is extracted from 'SHOW CREATE'.
+	if i.Percona() && compressible(ct) {
+		s := putShow(t)
+		s.compressible = append(s.compressible, c)
+	}
 	attr, err := parseExtra(extra.String)
 	if err != nil {
 		return err
@@ -300,6 +316,58 @@ func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) error {
 	return nil
 }
 
+// srids queries and sets the SRID attribute of the spatial columns in the schema.
+func (i *inspect) srids(ctx context.Context, s *schema.Schema) error {
+	if !i.SupportsSRID() || !hasSpatial(s) {
+		return nil
+	}
+	rows, err := i.querySchema(ctx, sridsQuery, s)
+	if err != nil {
+		return fmt.Errorf("mysql: query schema %q column srids: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id          int
+			table, name string
+		)
+		if err := rows.Scan(&table, &name, &id); err != nil {
+			return fmt.Errorf("mysql: scanning column srids for schema %q: %w", s.Name, err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("mysql: table %q was not found in schema", table)
+		}
+		c, ok := t.Column(name)
+		if !ok {
+			return fmt.Errorf("mysql: column %q was not found in table %q", name, table)
+		}
+		c.Attrs = append(c.Attrs, &SRID{ID: id})
+	}
+	return rows.Err()
+}
+
+// hasSpatial reports if the schema contains spatial columns.
+func hasSpatial(s *schema.Schema) bool {
+	for _, t := range s.Tables {
+		for _, c := range t.Columns {
+			if _, ok := c.Type.Type.(*schema.SpatialType); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compressible reports if columns of the given type can be compressed.
+func compressible(t schema.Type) bool {
+	switch t.(type) {
+	case *schema.StringType, *schema.BinaryType, *schema.JSONType:
+		return true
+	}
+	return false
+}
+
 // indexes queries and appends the indexes of the given table.
 func (i *inspect) indexes(ctx context.Context, s *schema.Schema) error {
 	query := i.indexQuery()
@@ -519,6 +587,9 @@ func (i *inspect) showCreate(ctx context.Context, s *schema.Schema) error {
 		if err := i.setAutoInc(st, t); err != nil {
 			return err
 		}
+		if err := i.setCompressed(st, t); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -550,6 +621,32 @@ func (i *inspect) setAutoInc(s *showTable, t *schema.Table) error {
 	return nil
 }
 
+// reCompressed matches the COMPRESSED attribute of MariaDB column types.
+var reCompressed = regexp.MustCompile(`(?i)\s*(?:/\*M?!\d+\s+)?\bCOMPRESSED\b(?:\s*\*/)?$`)
+
+// setCompressed extracts the COLUMN_FORMAT of the compressible columns from CREATE TABLE.
+func (i *inspect) setCompressed(s *showTable, t *schema.Table) error {
+	if len(s.compressible) == 0 {
+		return nil
+	}
+	var c CreateStmt
+	if !sqlx.Has(t.Attrs, &c) {
+		return fmt.Errorf("missing CREATE TABLE statement in attributes for %q", t.Name)
+	}
+	for _, l := range strings.Split(c.S, "\n") {
+		l = strings.TrimSpace(l)
+		for _, col := range s.compressible {
+			if strings.HasPrefix(l, "`"+strings.ReplaceAll(col.Name, "`", "``")+"` ") && reColumnFormat.MatchString(l) {
+				col.Attrs = append(col.Attrs, &Compressed{})
+			}
+		}
+	}
+	return nil
+}
+
+// reColumnFormat matches the compressed COLUMN_FORMAT in Percona Server column definitions.
+var reColumnFormat = regexp.MustCompile(`(?i)\bCOLUMN_FORMAT\s+COMPRESSED\b`)
+
 // createStmt loads the CREATE TABLE statement for the table.
 func (i *inspect) createStmt(ctx context.Context, t *schema.Table) error {
 	c := &CreateStmt{}
@@ -605,10 +702,10 @@ func parseColumn(typ string) (parts []string, size int, unsigned bool, err error
 		if attr := parts[len(parts)-1]; attr == "unsigned" || attr == "zerofill" {
 			unsigned = true
 		}
-		if len(parts) > 2 || len(parts) == 2 && !unsigned {
+		if len(parts) > 1 && parts[1] != "unsigned" && parts[1] != "zerofill" {
 			size, err = strconv.Atoi(parts[1])
 		}
-	case TypeBit, TypeBinary, TypeVarBinary, TypeChar, TypeVarchar:
+	case TypeBit, TypeBinary, TypeVarBinary, TypeChar, TypeVarchar, TypeNChar, TypeNVarchar:
 		if len(parts) > 1 {
 			size, err = strconv.Atoi(parts[1])
 		}
@@ -628,6 +725,12 @@ func hasNumericDefault(t schema.Type) bool {
 	return false
 }
 
+// isBool reports if the given type is a boolean type (i.e. tinyint(1)).
+func isBool(t schema.Type) bool {
+	_, ok := t.(*schema.BoolType)
+	return ok
+}
+
 func isHex(x string) bool { return len(x) > 2 && strings.ToLower(x[:2]) == "0x" }
 
 // marDefaultExpr returns the correct schema.Expr based on the column attributes for MariaDB.
@@ -676,7 +779,7 @@ func nArgs(n int) string { return strings.Repeat("?, ", n-1) + "?" }
 
 const (
 	// Query to list system variables.
-	variablesQuery = "SELECT @@version, @@collation_server, @@character_set_server"
+	variablesQuery = "SELECT @@version, @@collation_server, @@character_set_server, @@version_comment"
 
 	// Query to list database schemas.
 	schemasQuery = "SELECT `SCHEMA_NAME`, `DEFAULT_CHARACTER_SET_NAME`, `DEFAULT_COLLATION_NAME` from `INFORMATION_SCHEMA`.`SCHEMATA` WHERE `SCHEMA_NAME` NOT IN ('information_schema','innodb','mysql','performance_schema','sys') ORDER BY `SCHEMA_NAME`"
@@ -688,6 +791,9 @@ const (
 	columnsQuery     = "SELECT `TABLE_NAME`, `COLUMN_NAME`, `COLUMN_TYPE`, `COLUMN_COMMENT`, `IS_NULLABLE`, `COLUMN_KEY`, `COLUMN_DEFAULT`, `EXTRA`, `CHARACTER_SET_NAME`, `COLLATION_NAME`, NULL AS `GENERATION_EXPRESSION` FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `ORDINAL_POSITION`"
 	columnsExprQuery = "SELECT `TABLE_NAME`, `COLUMN_NAME`, `COLUMN_TYPE`, `COLUMN_COMMENT`, `IS_NULLABLE`, `COLUMN_KEY`, `COLUMN_DEFAULT`, `EXTRA`, `CHARACTER_SET_NAME`, `COLLATION_NAME`, `GENERATION_EXPRESSION` FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `ORDINAL_POSITION`"
 
+	// Query to list the SRID of spatial columns.
+	sridsQuery = "SELECT `TABLE_NAME`, `COLUMN_NAME`, `SRS_ID` FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) AND `SRS_ID` IS NOT NULL ORDER BY `ORDINAL_POSITION`"
+
 	// Query to list table indexes.
 	indexesQuery          = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, `INDEX_COMMENT`, `SUB_PART`, NULL AS `EXPRESSION` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
 	indexesExprQuery      = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, `INDEX_COMMENT`, `SUB_PART`, `EXPRESSION` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
@@ -786,6 +892,13 @@ type (
 		V int64
 	}
 
+	// AutoRandom attribute for TiDB primary-key columns with "AUTO_RANDOM" as a default.
+	// ShardBits holds the optional number of shard bits. Zero means the TiDB default.
+	AutoRandom struct {
+		schema.Attr
+		ShardBits int
+	}
+
 	// CreateOptions attribute for describing extra options used with CREATE TABLE.
 	CreateOptions struct {
 		schema.Attr
@@ -829,6 +942,96 @@ type (
 		A string
 	}
 
+	// ColumnPosition attribute defines the position of a column in its table.
+	// It is used by the planner when adding columns to existing tables, i.e.
+	// "ADD COLUMN ... FIRST" or "ADD COLUMN ... AFTER `c`".
+	ColumnPosition struct {
+		schema.Attr
+		First bool   // First indicates the column is placed first.
+		After string // After holds the name of the preceding column.
+	}
+
+	// Compressed attribute marks a column as compressed. It is supported by MariaDB
+	// ("data_type COMPRESSED") and Percona Server ("COLUMN_FORMAT COMPRESSED") for
+	// string, binary and JSON columns.
+	Compressed struct {
+		schema.Attr
+	}
+
+	// Encryption attribute describes the InnoDB ENCRYPTION table option.
+	Encryption struct {
+		schema.Attr
+		V bool // ENCRYPTION='Y' or ENCRYPTION='N'.
+	}
+
+	// Compression attribute describes the InnoDB page COMPRESSION table
+	// option. Not to be confused with the Compressed column attribute.
+	Compression struct {
+		schema.Attr
+		V string // zlib, lz4 or none.
+	}
+
+	// StatsPersistent attribute describes the InnoDB STATS_PERSISTENT table option.
+	StatsPersistent struct {
+		schema.Attr
+		V string // DEFAULT, 0 or 1.
+	}
+
+	// StatsAutoRecalc attribute describes the InnoDB STATS_AUTO_RECALC table option.
+	StatsAutoRecalc struct {
+		schema.Attr
+		V string // DEFAULT, 0 or 1.
+	}
+
+	// StatsSamplePages attribute describes the InnoDB STATS_SAMPLE_PAGES table option.
+	StatsSamplePages struct {
+		schema.Attr
+		V string // DEFAULT or the number of pages.
+	}
+
+	// Union attribute describes the UNION table option of MERGE tables,
+	// i.e. the list of the underlying tables.
+	Union struct {
+		schema.Attr
+		T []string
+	}
+
+	// InsertMethod attribute describes the INSERT_METHOD table option of MERGE tables.
+	InsertMethod struct {
+		schema.Attr
+		V string // NO, FIRST or LAST.
+	}
+
+	// AvgRowLength attribute describes the AVG_ROW_LENGTH table option.
+	AvgRowLength struct {
+		schema.Attr
+		V uint64
+	}
+
+	// MaxRows attribute describes the MAX_ROWS table option.
+	MaxRows struct {
+		schema.Attr
+		V uint64
+	}
+
+	// MinRows attribute describes the MIN_ROWS table option.
+	MinRows struct {
+		schema.Attr
+		V uint64
+	}
+
+	// Temporary attribute marks a table as temporary, i.e. "CREATE TEMPORARY TABLE".
+	Temporary struct {
+		schema.Attr
+	}
+
+	// SRID attribute restricts the values of a spatial column to the given
+	// spatial reference system identifier, e.g. "POINT NOT NULL SRID 4326".
+	SRID struct {
+		schema.Attr
+		ID int
+	}
+
 	// IndexType represents an index type.
 	IndexType struct {
 		schema.Attr
@@ -855,6 +1058,8 @@ type (
 		schema.Attr
 		// AUTO_INCREMENT value to due missing value in information_schema.
 		auto *AutoIncrement
+		// Columns that their COLUMN_FORMAT is missing in information_schema.
+		compressible []*schema.Column
 	}
 )
 
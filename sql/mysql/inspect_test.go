@@ -7,6 +7,7 @@ package mysql
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/internal/sqltest"
@@ -22,6 +23,7 @@ var (
 	queryTable            = sqltest.Escape(fmt.Sprintf(tablesQuery, "?"))
 	queryColumns          = sqltest.Escape(fmt.Sprintf(columnsExprQuery, "?"))
 	queryColumnsNoExpr    = sqltest.Escape(fmt.Sprintf(columnsQuery, "?"))
+	querySRIDs            = sqltest.Escape(fmt.Sprintf(sridsQuery, "?"))
 	queryIndexes          = sqltest.Escape(fmt.Sprintf(indexesQuery, "?"))
 	queryIndexesNoComment = sqltest.Escape(fmt.Sprintf(indexesNoCommentQuery, "?"))
 	queryIndexesExpr      = sqltest.Escape(fmt.Sprintf(indexesExprQuery, "?"))
@@ -466,6 +468,15 @@ func TestDriver_InspectTable(t *testing.T) {
 | users      | c8          | geometrycollection |                | NO          |            | NULL           |       | NULL               | NULL           | NULL                      |
 | users      | c9          | geomcollection     |                | NO          |            | NULL           |       | NULL               | NULL           | NULL                      |
 +------------+-------------+--------------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+---------------------------+
+`))
+				m.ExpectQuery(querySRIDs).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
++------------+-------------+--------+
+| TABLE_NAME | COLUMN_NAME | SRS_ID |
++------------+-------------+--------+
+| users      | c1          | 4326   |
++------------+-------------+--------+
 `))
 				m.noIndexes()
 				m.noFKs()
@@ -474,7 +485,7 @@ func TestDriver_InspectTable(t *testing.T) {
 				require.NoError(err)
 				require.Equal("users", t.Name)
 				require.EqualValues([]*schema.Column{
-					{Name: "c1", Type: &schema.ColumnType{Raw: "point", Type: &schema.SpatialType{T: "point"}}},
+					{Name: "c1", Type: &schema.ColumnType{Raw: "point", Type: &schema.SpatialType{T: "point"}}, Attrs: []schema.Attr{&SRID{ID: 4326}}},
 					{Name: "c2", Type: &schema.ColumnType{Raw: "multipoint", Type: &schema.SpatialType{T: "multipoint"}}},
 					{Name: "c3", Type: &schema.ColumnType{Raw: "linestring", Type: &schema.SpatialType{T: "linestring"}}},
 					{Name: "c4", Type: &schema.ColumnType{Raw: "multilinestring", Type: &schema.SpatialType{T: "multilinestring"}}},
@@ -1030,18 +1041,179 @@ func TestInspectMode_InspectRealm(t *testing.T) {
 	}(), realm)
 }
 
+func TestDriver_InspectSRID(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("8.0.19")
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= ?"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| test        | utf8mb4                    | utf8mb4_unicode_ci     |
++-------------+----------------------------+------------------------+
+`))
+	mk.tableExists("test", "places", true)
+	mk.ExpectQuery(queryColumns).
+		WithArgs("test", "places").
+		WillReturnRows(sqltest.Rows(`
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+-----------------------+
+| TABLE_NAME | COLUMN_NAME | COLUMN_TYPE | COLUMN_COMMENT | IS_NULLABLE | COLUMN_KEY | COLUMN_DEFAULT | EXTRA | CHARACTER_SET_NAME | COLLATION_NAME | GENERATION_EXPRESSION |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+-----------------------+
+| places     | location    | point       |                | NO          |            | NULL           |       | NULL               | NULL           | NULL                  |
+| places     | area        | polygon     |                | YES         |            | NULL           |       | NULL               | NULL           | NULL                  |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+-----------------------+
+`))
+	mk.ExpectQuery(querySRIDs).
+		WithArgs("test", "places").
+		WillReturnRows(sqltest.Rows(`
++------------+-------------+--------+
+| TABLE_NAME | COLUMN_NAME | SRS_ID |
++------------+-------------+--------+
+| places     | location    | 4326   |
++------------+-------------+--------+
+`))
+	mk.noIndexes()
+	mk.noFKs()
+	mk.ExpectQuery(queryMyChecks).
+		WithArgs("test", "places").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	inspected, err := drv.InspectSchema(context.Background(), "test", nil)
+	require.NoError(t, err)
+
+	var desired schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "places" {
+  schema = schema.test
+  column "location" {
+    type = point
+    srid = 4326
+  }
+  column "area" {
+    type = polygon
+    null = true
+  }
+}
+`), &desired, nil))
+	changes, err := DefaultDiff.TableDiff(inspected.Tables[0], desired.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	desired.Tables[0].Columns[0].Attrs = []schema.Attr{&SRID{ID: 3857}}
+	changes, err = DefaultDiff.TableDiff(inspected.Tables[0], desired.Tables[0])
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.True(t, changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeType))
+}
+
+func TestDriver_InspectCompressed(t *testing.T) {
+	for _, tt := range []struct {
+		version, comment, body string
+		before                 func(mock)
+	}{
+		{
+			version: "10.7.1-MariaDB",
+			comment: "mariadb.org binary distribution",
+			body:    "blob /*M!100301 COMPRESSED*/",
+			before: func(m mock) {
+				m.ExpectQuery(queryIndexes).
+					WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression"}))
+				m.noFKs()
+				m.ExpectQuery(queryMarChecks).
+					WithArgs("public", "docs").
+					WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"}))
+			},
+		},
+		{
+			version: "8.0.28-19",
+			comment: "Percona Server (GPL), Release 19, Revision 31e88966cd3",
+			body:    "blob",
+			before: func(m mock) {
+				m.noIndexes()
+				m.noFKs()
+				m.ExpectQuery(queryMyChecks).
+					WithArgs("public", "docs").
+					WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"}))
+				m.ExpectQuery(sqltest.Escape("SHOW CREATE TABLE `public`.`docs`")).
+					WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow("docs", strings.Join([]string{
+						"CREATE TABLE `docs` (",
+						"  `body` blob NOT NULL /*!50633 COLUMN_FORMAT COMPRESSED */,",
+						"  `title` varchar(255) NOT NULL",
+						") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+					}, "\n")))
+			},
+		},
+	} {
+		t.Run(tt.version, func(t *testing.T) {
+			db, m, err := sqlmock.New()
+			require.NoError(t, err)
+			mk := mock{m}
+			mk.versionComment(tt.version, tt.comment)
+			mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= ?"))).
+				WithArgs("public").
+				WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| public      | utf8mb4                    | utf8mb4_unicode_ci     |
++-------------+----------------------------+------------------------+
+`))
+			mk.tableExists("public", "docs", true)
+			mk.ExpectQuery(queryColumns).
+				WithArgs("public", "docs").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "COLUMN_COMMENT", "IS_NULLABLE", "COLUMN_KEY", "COLUMN_DEFAULT", "EXTRA", "CHARACTER_SET_NAME", "COLLATION_NAME", "GENERATION_EXPRESSION"}).
+					AddRow("docs", "body", tt.body, "", "NO", "", nil, "", nil, nil, nil).
+					AddRow("docs", "title", "varchar(255)", "", "NO", "", nil, "", nil, nil, nil))
+			tt.before(mk)
+			drv, err := Open(db)
+			require.NoError(t, err)
+			s, err := drv.InspectSchema(context.Background(), "public", nil)
+			require.NoError(t, err)
+			body, title := s.Tables[0].Columns[0], s.Tables[0].Columns[1]
+			require.Equal(t, &schema.BinaryType{T: TypeBlob}, body.Type.Type)
+			require.Equal(t, []schema.Attr{&Compressed{}}, body.Attrs)
+			require.Empty(t, title.Attrs)
+
+			// Compression changes are detected by the diff.
+			to := schema.NewTable("docs").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					schema.NewBinaryColumn("body", TypeBlob),
+					schema.NewStringColumn("title", TypeVarchar, schema.StringSize(255)),
+				)
+			changes, err := drv.TableDiff(s.Tables[0], to)
+			require.NoError(t, err)
+			require.Len(t, changes, 1)
+			require.True(t, changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeAttr))
+			to.Columns[0].AddAttrs(&Compressed{})
+			changes, err = drv.TableDiff(s.Tables[0], to)
+			require.NoError(t, err)
+			require.Empty(t, changes)
+		})
+	}
+}
+
 type mock struct {
 	sqlmock.Sqlmock
 }
 
 func (m mock) version(version string) {
+	m.versionComment(version, "Source distribution")
+}
+
+func (m mock) versionComment(version, comment string) {
 	m.ExpectQuery(sqltest.Escape(variablesQuery)).
 		WillReturnRows(sqltest.Rows(`
-+-----------------+--------------------+------------------------+
-| @@version       | @@collation_server | @@character_set_server |
-+-----------------+--------------------+------------------------+
-| ` + version + ` | utf8_general_ci    | utf8                   |
-+-----------------+--------------------+------------------------+
++-----------------+--------------------+------------------------+-------------------+
+| @@version       | @@collation_server | @@character_set_server | @@version_comment |
++-----------------+--------------------+------------------------+-------------------+
+| ` + version + ` | utf8_general_ci    | utf8                   | ` + comment + ` |
++-----------------+--------------------+------------------------+-------------------+
 `))
 }
 
@@ -76,6 +76,12 @@ func (v V) SupportsRenameColumn() bool {
 	return v.GTE(u)
 }
 
+// SupportsSRID reports if the version supports the SRID column
+// attribute, and exposes it in the information schema.
+func (v V) SupportsSRID() bool {
+	return !v.Maria() && v.GTE("8.0.3")
+}
+
 // SupportsIndexComment reports if the version
 // supports comments on indexes.
 func (v V) SupportsIndexComment() bool {
@@ -102,6 +108,17 @@ func (v V) CollateToCharset() (map[string]string, error) {
 	return decode(name)
 }
 
+// TypesSince returns the mapping from a type name to the minimum version
+// supporting it. Types that are missing from the mapping are supported by
+// all versions.
+func (v V) TypesSince() (map[string]string, error) {
+	name := "is/types"
+	if v.Maria() {
+		name += ".maria"
+	}
+	return decode(name)
+}
+
 // Maria reports if the MySQL version is MariaDB.
 func (v V) Maria() bool {
 	return strings.Index(string(v), "MariaDB") > 0
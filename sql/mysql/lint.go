@@ -0,0 +1,189 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"sort"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// ForeignKeyCycles returns the groups of tables in the schema that reference
+// each other through foreign keys, directly (A→B→A) or indirectly (A→B→C→A).
+// MySQL accepts such definitions, but the tables in each group cannot be created
+// in a simple dependency order and require their foreign keys to be added after
+// the tables were created. Self-references are not reported. The table names in
+// each group and the groups themselves are sorted.
+func ForeignKeyCycles(s *schema.Schema) [][]string {
+	var (
+		idx    int
+		stack  []*schema.Table
+		cycles [][]string
+		index  = make(map[*schema.Table]int)
+		low    = make(map[*schema.Table]int)
+		on     = make(map[*schema.Table]bool)
+		in     = make(map[*schema.Table]bool, len(s.Tables))
+		visit  func(*schema.Table)
+	)
+	for _, t := range s.Tables {
+		in[t] = true
+	}
+	// Tarjan's algorithm for finding the strongly connected components of the graph.
+	visit = func(t *schema.Table) {
+		index[t], low[t] = idx, idx
+		idx++
+		stack = append(stack, t)
+		on[t] = true
+		for _, fk := range t.ForeignKeys {
+			r := fk.RefTable
+			if r == nil || r == t || !in[r] {
+				continue
+			}
+			if _, ok := index[r]; !ok {
+				visit(r)
+				if low[r] < low[t] {
+					low[t] = low[r]
+				}
+			} else if on[r] && index[r] < low[t] {
+				low[t] = index[r]
+			}
+		}
+		if low[t] != index[t] {
+			return
+		}
+		var names []string
+		for {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			on[n] = false
+			names = append(names, n.Name)
+			if n == t {
+				break
+			}
+		}
+		if len(names) > 1 {
+			sort.Strings(names)
+			cycles = append(cycles, names)
+		}
+	}
+	for _, t := range s.Tables {
+		if _, ok := index[t]; !ok {
+			visit(t)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
+
+// ColumnsWithAttr returns the columns of all tables in the schema that have an
+// attribute that matches the given predicate, in their definition order. For
+// example, the following returns all auto-increment columns:
+//
+//	ColumnsWithAttr(s, func(a schema.Attr) bool {
+//		_, ok := a.(*AutoIncrement)
+//		return ok
+//	})
+func ColumnsWithAttr(s *schema.Schema, match func(schema.Attr) bool) []*schema.Column {
+	var columns []*schema.Column
+	for _, t := range s.Tables {
+		for _, c := range t.Columns {
+			for _, a := range c.Attrs {
+				if match(a) {
+					columns = append(columns, c)
+					break
+				}
+			}
+		}
+	}
+	return columns
+}
+
+// RedundantIndexes returns the groups of redundant indexes in the table. An index is
+// redundant if another index of the same type covers it, i.e. its parts are identical
+// to the other index parts, or to their leftmost prefix. Unique indexes are redundant
+// only if they are duplicates of another unique index (or the primary key), as they
+// enforce a constraint. The first name in each group is the index that covers the
+// rest, which can be dropped. The redundant names in each group and the groups
+// themselves are sorted. The primary key is reported as "PRIMARY".
+func RedundantIndexes(t *schema.Table) [][]string {
+	idxs := make([]*schema.Index, 0, len(t.Indexes)+1)
+	if pk := t.PrimaryKey; pk != nil {
+		idxs = append(idxs, &schema.Index{Name: "PRIMARY", Unique: true, Parts: pk.Parts, Attrs: pk.Attrs})
+	}
+	idxs = append(idxs, t.Indexes...)
+	// Indexes that may cover others come first: the primary
+	// key, then unique indexes, and then the longest ones.
+	sort.SliceStable(idxs, func(i, j int) bool {
+		if idxs[i].Unique != idxs[j].Unique {
+			return idxs[i].Unique
+		}
+		return len(idxs[i].Parts) > len(idxs[j].Parts)
+	})
+	var (
+		kept   []*schema.Index
+		groups = make(map[*schema.Index][]string)
+	)
+Next:
+	for _, idx := range idxs {
+		for _, k := range kept {
+			if coversIndex(k, idx) {
+				groups[k] = append(groups[k], idx.Name)
+				continue Next
+			}
+		}
+		kept = append(kept, idx)
+	}
+	var redundant [][]string
+	for _, k := range kept {
+		if names := groups[k]; len(names) > 0 {
+			sort.Strings(names)
+			redundant = append(redundant, append([]string{k.Name}, names...))
+		}
+	}
+	sort.Slice(redundant, func(i, j int) bool {
+		return redundant[i][0] < redundant[j][0]
+	})
+	return redundant
+}
+
+// coversIndex reports if index a covers index b.
+func coversIndex(a, b *schema.Index) bool {
+	switch {
+	case len(b.Parts) == 0 || len(a.Parts) < len(b.Parts):
+		return false
+	case b.Unique && (!a.Unique || len(a.Parts) != len(b.Parts)):
+		return false
+	case indexType(a.Attrs).T != indexType(b.Attrs).T:
+		return false
+	}
+	for i, p := range b.Parts {
+		if !samePart(a.Parts[i], p) {
+			return false
+		}
+	}
+	return true
+}
+
+// samePart reports if the two index parts index the same key.
+func samePart(p1, p2 *schema.IndexPart) bool {
+	var s1, s2 SubPart
+	sqlx.Has(p1.Attrs, &s1)
+	sqlx.Has(p2.Attrs, &s2)
+	if p1.Desc != p2.Desc || s1.Len != s2.Len {
+		return false
+	}
+	switch {
+	case p1.C != nil && p2.C != nil:
+		return p1.C.Name == p2.C.Name
+	case p1.X != nil && p2.X != nil:
+		x1, ok1 := p1.X.(*schema.RawExpr)
+		x2, ok2 := p2.X.(*schema.RawExpr)
+		return ok1 && ok2 && x1.X == x2.X
+	}
+	return false
+}
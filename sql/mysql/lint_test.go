@@ -0,0 +1,205 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForeignKeyCycles(t *testing.T) {
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  column "team_id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  foreign_key "team" {
+    columns     = [column.team_id]
+    ref_columns = [table.teams.column.id]
+  }
+}
+table "teams" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  column "owner_id" {
+    type = int
+  }
+  column "parent_id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  foreign_key "owner" {
+    columns     = [column.owner_id]
+    ref_columns = [table.users.column.id]
+  }
+  foreign_key "parent" {
+    columns     = [column.parent_id]
+    ref_columns = [table.teams.column.id]
+  }
+}
+table "posts" {
+  schema = schema.test
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+`), &s, nil))
+	require.Equal(t, [][]string{{"teams", "users"}}, ForeignKeyCycles(&s))
+
+	// Self-references and acyclic references are not reported.
+	s.Tables[1].ForeignKeys = s.Tables[1].ForeignKeys[1:]
+	require.Empty(t, ForeignKeyCycles(&s))
+}
+
+func TestColumnsWithAttr(t *testing.T) {
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type           = int
+    auto_increment = true
+  }
+  column "name" {
+    type = varchar(255)
+  }
+  column "updated_at" {
+    type      = timestamp
+    default   = sql("CURRENT_TIMESTAMP")
+    on_update = sql("CURRENT_TIMESTAMP")
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+table "posts" {
+  schema = schema.test
+  column "id" {
+    type           = bigint
+    auto_increment = true
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+`), &s, nil))
+	names := func(cs []*schema.Column) (names []string) {
+		for _, c := range cs {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+	autoInc := ColumnsWithAttr(&s, func(a schema.Attr) bool {
+		_, ok := a.(*AutoIncrement)
+		return ok
+	})
+	require.Len(t, autoInc, 2)
+	users, _ := s.Table("users")
+	require.Equal(t, users.Columns[0], autoInc[0])
+	require.Equal(t, []string{"id", "id"}, names(autoInc))
+	onUpdate := ColumnsWithAttr(&s, func(a schema.Attr) bool {
+		_, ok := a.(*OnUpdate)
+		return ok
+	})
+	require.Equal(t, []string{"updated_at"}, names(onUpdate))
+	require.Empty(t, ColumnsWithAttr(&s, func(a schema.Attr) bool {
+		_, ok := a.(*schema.Comment)
+		return ok
+	}))
+}
+
+func TestRedundantIndexes(t *testing.T) {
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  column "name" {
+    type = varchar(255)
+  }
+  column "email" {
+    type = varchar(255)
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "id" {
+    columns = [column.id]
+  }
+  index "name_email" {
+    columns = [column.name, column.email]
+  }
+  index "name" {
+    columns = [column.name]
+  }
+  index "name_dup" {
+    columns = [column.name]
+  }
+  index "name_prefix" {
+    on {
+      column = column.name
+      prefix = 10
+    }
+  }
+  index "name_desc" {
+    on {
+      column = column.name
+      desc   = true
+    }
+  }
+  index "email" {
+    unique  = true
+    columns = [column.email]
+  }
+  index "email_name" {
+    columns = [column.email, column.name]
+  }
+  index "email_dup" {
+    unique  = true
+    columns = [column.email]
+  }
+  index "email_text" {
+    type    = FULLTEXT
+    columns = [column.email]
+  }
+}
+`), &s, nil))
+	require.Equal(t, [][]string{
+		{"PRIMARY", "id"},
+		{"email", "email_dup"},
+		{"name_email", "name", "name_dup"},
+	}, RedundantIndexes(s.Tables[0]))
+
+	tbl := schema.NewTable("t").AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"))
+	tbl.AddIndexes(
+		schema.NewIndex("a_b").AddColumns(tbl.Columns...),
+		schema.NewIndex("b").AddColumns(tbl.Columns[1]),
+	)
+	require.Empty(t, RedundantIndexes(tbl))
+}
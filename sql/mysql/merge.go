@@ -0,0 +1,277 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// MergeSchemas returns a new schema that overlays the tables of overlay onto base.
+// Tables, columns, indexes, foreign keys and checks that exist only in overlay are
+// added to their base counterparts, and the ones that exist in both must be defined
+// identically. Otherwise, a conflict error is returned. The given schemas are not
+// modified, and elements that exist in both are taken from base.
+func MergeSchemas(base, overlay *schema.Schema) (*schema.Schema, error) {
+	if overlay.Name != "" && overlay.Name != base.Name {
+		return nil, fmt.Errorf("mysql: cannot merge schema %q into schema %q", overlay.Name, base.Name)
+	}
+	m := &merger{
+		s:       schema.New(base.Name).SetRealm(base.Realm),
+		tables:  make(map[*schema.Table]*schema.Table),
+		columns: make(map[*schema.Column]*schema.Column),
+	}
+	for _, s := range []*schema.Schema{base, overlay} {
+		if err := mergeAttrs(&m.s.Attrs, s.Attrs, fmt.Sprintf("schema %q", base.Name)); err != nil {
+			return nil, err
+		}
+		for _, t := range s.Tables {
+			if err := m.table(t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// Foreign keys are merged last, as they may reference tables that were added by overlay.
+	for _, s := range []*schema.Schema{base, overlay} {
+		for _, t := range s.Tables {
+			for _, fk := range t.ForeignKeys {
+				if err := m.foreignKey(fk); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return m.s, nil
+}
+
+// merger holds the state of MergeSchemas. The tables and columns
+// maps link the input elements to their copies in the merged schema.
+type merger struct {
+	s       *schema.Schema
+	tables  map[*schema.Table]*schema.Table
+	columns map[*schema.Column]*schema.Column
+}
+
+func (m *merger) table(t *schema.Table) error {
+	nt, ok := m.s.Table(t.Name)
+	if !ok {
+		nt = schema.NewTable(t.Name)
+		m.s.AddTables(nt)
+	}
+	m.tables[t] = nt
+	if err := mergeAttrs(&nt.Attrs, t.Attrs, fmt.Sprintf("table %q", t.Name)); err != nil {
+		return err
+	}
+	for _, c := range t.Columns {
+		if nc, ok := nt.Column(c.Name); ok {
+			// The diff normalizes the columns it compares (e.g. adds
+			// their default collation). Hence, copies are compared.
+			tc := &schema.Table{Name: nt.Name, Schema: nt.Schema, Attrs: append([]schema.Attr(nil), nt.Attrs...)}
+			change, err := (&diff{}).ColumnChange(tc, copyColumn(nc), copyColumn(c))
+			if err != nil {
+				return err
+			}
+			if change != schema.NoChange {
+				return fmt.Errorf("mysql: conflicting definitions for column %q of table %q", c.Name, t.Name)
+			}
+			m.columns[c] = nc
+			continue
+		}
+		nc := copyColumn(c)
+		nt.AddColumns(nc)
+		m.columns[c] = nc
+	}
+	if pk := t.PrimaryKey; pk != nil {
+		switch {
+		case nt.PrimaryKey == nil:
+			nt.SetPrimaryKey(m.index(pk))
+		case !sameIndex(nt.PrimaryKey, pk):
+			return fmt.Errorf("mysql: conflicting primary keys for table %q", t.Name)
+		}
+	}
+	for _, idx := range t.Indexes {
+		switch nidx, ok := nt.Index(idx.Name); {
+		case !ok:
+			nt.AddIndexes(m.index(idx))
+		case !sameIndex(nidx, idx):
+			return fmt.Errorf("mysql: conflicting definitions for index %q of table %q", idx.Name, t.Name)
+		}
+	}
+	return nil
+}
+
+// copyColumn returns a copy of the column, its type and its attributes,
+// that is not linked to the table, indexes and foreign keys of the column.
+func copyColumn(c *schema.Column) *schema.Column {
+	nc := &schema.Column{
+		Name:    c.Name,
+		Default: c.Default,
+		Attrs:   append([]schema.Attr(nil), c.Attrs...),
+	}
+	if c.Type != nil {
+		nc.Type = &schema.ColumnType{
+			Type: copyType(c.Type.Type),
+			Raw:  c.Type.Raw,
+			Null: c.Type.Null,
+		}
+	}
+	return nc
+}
+
+// copyType returns a shallow copy of the column type, as the
+// diff may normalize the types it compares (e.g. int to int(10)).
+func copyType(t schema.Type) schema.Type {
+	switch t := t.(type) {
+	case *BitType:
+		nt := *t
+		return &nt
+	case *SetType:
+		nt := *t
+		return &nt
+	case *schema.BinaryType:
+		nt := *t
+		return &nt
+	case *schema.BoolType:
+		nt := *t
+		return &nt
+	case *schema.DecimalType:
+		nt := *t
+		return &nt
+	case *schema.EnumType:
+		nt := *t
+		return &nt
+	case *schema.FloatType:
+		nt := *t
+		return &nt
+	case *schema.IntegerType:
+		nt := *t
+		return &nt
+	case *schema.JSONType:
+		nt := *t
+		return &nt
+	case *schema.SpatialType:
+		nt := *t
+		return &nt
+	case *schema.StringType:
+		nt := *t
+		return &nt
+	case *schema.TimeType:
+		nt := *t
+		return &nt
+	case *schema.UUIDType:
+		nt := *t
+		return &nt
+	case *schema.UnsupportedType:
+		nt := *t
+		return &nt
+	default:
+		return t
+	}
+}
+
+// index returns a copy of the index that is linked to the merged columns.
+func (m *merger) index(idx *schema.Index) *schema.Index {
+	nidx := &schema.Index{Name: idx.Name, Unique: idx.Unique, Attrs: append([]schema.Attr(nil), idx.Attrs...)}
+	for _, p := range idx.Parts {
+		np := &schema.IndexPart{SeqNo: p.SeqNo, Desc: p.Desc, X: p.X, Attrs: append([]schema.Attr(nil), p.Attrs...)}
+		if c, ok := m.columns[p.C]; ok {
+			np.C = c
+			c.Indexes = append(c.Indexes, nidx)
+		}
+		nidx.Parts = append(nidx.Parts, np)
+	}
+	return nidx
+}
+
+func (m *merger) foreignKey(fk *schema.ForeignKey) error {
+	nt := m.tables[fk.Table]
+	nfk := &schema.ForeignKey{Symbol: fk.Symbol, RefTable: fk.RefTable, OnUpdate: fk.OnUpdate, OnDelete: fk.OnDelete}
+	// Tables that are not part of the merged schemas are kept as-is.
+	if t, ok := m.tables[fk.RefTable]; ok {
+		nfk.RefTable = t
+	}
+	for _, c := range fk.Columns {
+		nfk.Columns = append(nfk.Columns, m.columns[c])
+	}
+	for _, c := range fk.RefColumns {
+		if nc, ok := m.columns[c]; ok {
+			c = nc
+		}
+		nfk.RefColumns = append(nfk.RefColumns, c)
+	}
+	if cur, ok := nt.ForeignKey(fk.Symbol); ok {
+		if !sameForeignKey(cur, nfk) {
+			return fmt.Errorf("mysql: conflicting definitions for foreign key %q of table %q", fk.Symbol, nt.Name)
+		}
+		return nil
+	}
+	nt.AddForeignKeys(nfk)
+	for _, c := range nfk.Columns {
+		c.ForeignKeys = append(c.ForeignKeys, nfk)
+	}
+	return nil
+}
+
+// mergeAttrs merges the src attributes into dst. Checks are matched by their names,
+// and other attributes by their types. The owner is used for reporting conflicts.
+func mergeAttrs(dst *[]schema.Attr, src []schema.Attr, owner string) error {
+Next:
+	for _, a := range src {
+		for _, cur := range *dst {
+			c1, ok1 := cur.(*schema.Check)
+			c2, ok2 := a.(*schema.Check)
+			switch {
+			case ok1 && ok2 && c1.Name == c2.Name && (c1.Name != "" || c1.Expr == c2.Expr):
+				if c1.Expr != c2.Expr {
+					return fmt.Errorf("mysql: conflicting definitions for check %q of %s", c2.Name, owner)
+				}
+				continue Next
+			case !ok1 && !ok2 && reflect.TypeOf(cur) == reflect.TypeOf(a):
+				if !reflect.DeepEqual(cur, a) {
+					return fmt.Errorf("mysql: conflicting %s attributes for %s", strings.TrimPrefix(reflect.TypeOf(a).String(), "*"), owner)
+				}
+				continue Next
+			}
+		}
+		*dst = append(*dst, a)
+	}
+	return nil
+}
+
+// sameIndex reports if the two indexes are defined identically.
+func sameIndex(idx1, idx2 *schema.Index) bool {
+	if idx1.Unique != idx2.Unique || len(idx1.Parts) != len(idx2.Parts) || indexType(idx1.Attrs).T != indexType(idx2.Attrs).T {
+		return false
+	}
+	for i := range idx1.Parts {
+		if !samePart(idx1.Parts[i], idx2.Parts[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameForeignKey reports if the two foreign keys are defined identically.
+func sameForeignKey(fk1, fk2 *schema.ForeignKey) bool {
+	d := &diff{}
+	if fk1.RefTable.Name != fk2.RefTable.Name || len(fk1.Columns) != len(fk2.Columns) || len(fk1.RefColumns) != len(fk2.RefColumns) ||
+		d.ReferenceChanged(fk1.OnUpdate, fk2.OnUpdate) || d.ReferenceChanged(fk1.OnDelete, fk2.OnDelete) {
+		return false
+	}
+	for i := range fk1.Columns {
+		if fk1.Columns[i].Name != fk2.Columns[i].Name {
+			return false
+		}
+	}
+	for i := range fk1.RefColumns {
+		if fk1.RefColumns[i].Name != fk2.RefColumns[i].Name {
+			return false
+		}
+	}
+	return true
+}
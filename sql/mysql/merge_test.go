@@ -0,0 +1,156 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSchemas(t *testing.T) {
+	var base, overlay schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "app" {}
+table "users" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+  column "name" {
+    type = varchar(255)
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+`), &base, nil))
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "app" {}
+table "users" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+  column "email" {
+    type = varchar(255)
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "email" {
+    unique  = true
+    columns = [column.email]
+  }
+}
+table "posts" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+`), &overlay, nil))
+	merged, err := MergeSchemas(&base, &overlay)
+	require.NoError(t, err)
+	buf, err := MarshalHCL.MarshalSpec(merged)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.app
+  column "id" {
+    null = false
+    type = int
+  }
+  column "name" {
+    null = false
+    type = varchar(255)
+  }
+  column "email" {
+    null = false
+    type = varchar(255)
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "email" {
+    unique  = true
+    columns = [column.email]
+  }
+}
+table "posts" {
+  schema = schema.app
+  column "id" {
+    null = false
+    type = int
+  }
+  column "author_id" {
+    null = false
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+schema "app" {
+}
+`, string(buf))
+	users, posts := merged.Tables[0], merged.Tables[1]
+	require.Equal(t, users, posts.ForeignKeys[0].RefTable)
+	require.Equal(t, users.Columns[0], posts.ForeignKeys[0].RefColumns[0])
+	require.Equal(t, users.Columns[2], users.Indexes[0].Parts[0].C)
+	// The given schemas are not modified.
+	require.Len(t, base.Tables, 1)
+	require.Len(t, base.Tables[0].Columns, 2)
+	require.Len(t, overlay.Tables[0].Columns, 2)
+
+	overlay.Tables[0].Columns[0].Type.Type = &schema.IntegerType{T: TypeBigInt}
+	_, err = MergeSchemas(&base, &overlay)
+	require.EqualError(t, err, `mysql: conflicting definitions for column "id" of table "users"`)
+	overlay.Tables[0].Columns[0].Type.Type = &schema.IntegerType{T: TypeInt}
+	overlay.Tables[0].SetComment("users")
+	base.Tables[0].SetComment("accounts")
+	_, err = MergeSchemas(&base, &overlay)
+	require.EqualError(t, err, `mysql: conflicting schema.Comment attributes for table "users"`)
+	_, err = MergeSchemas(&base, schema.New("other"))
+	require.EqualError(t, err, `mysql: cannot merge schema "other" into schema "app"`)
+}
+
+func TestMergeSchemas_Inputs(t *testing.T) {
+	newBase := func() *schema.Schema {
+		users := schema.NewTable("users").
+			AddColumns(
+				schema.NewIntColumn("id", "int(10)"),
+				schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)),
+			)
+		return schema.New("app").AddTables(users)
+	}
+	newOverlay := func() *schema.Schema {
+		users := schema.NewTable("users").
+			AddColumns(
+				schema.NewIntColumn("id", TypeInt),
+				schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)).SetCharset("utf8mb4"),
+				schema.NewStringColumn("email", TypeVarchar, schema.StringSize(255)).SetCharset("utf8mb4"),
+			)
+		return schema.New("app").AddTables(users)
+	}
+	base, overlay := newBase(), newOverlay()
+	merged, err := MergeSchemas(base, overlay)
+	require.NoError(t, err)
+	require.Len(t, merged.Tables[0].Columns, 3)
+	// The given schemas are not modified, and do not share their columns with the merged schema.
+	require.Equal(t, newBase(), base)
+	require.Equal(t, newOverlay(), overlay)
+	require.NotSame(t, base.Tables[0].Columns[0].Type, merged.Tables[0].Columns[0].Type)
+	require.NotSame(t, overlay.Tables[0].Columns[2].Type, merged.Tables[0].Columns[2].Type)
+}
@@ -207,8 +207,12 @@ func (s *state) modifySchema(modify *schema.ModifySchema) error {
 func (s *state) addTable(add *schema.AddTable) error {
 	var (
 		errs []string
-		b    = s.Build("CREATE TABLE")
+		b    = s.Build("CREATE")
 	)
+	if sqlx.Has(add.T.Attrs, &Temporary{}) {
+		b.P("TEMPORARY")
+	}
+	b.P("TABLE")
 	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
 		b.P("IF NOT EXISTS")
 	}
@@ -349,6 +353,14 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				if err := s.column(b, t, change.C); err != nil {
 					return err
 				}
+				if p := (ColumnPosition{}); sqlx.Has(change.C.Attrs, &p) {
+					switch {
+					case p.First:
+						b.P("FIRST")
+					case p.After != "":
+						b.P("AFTER").Ident(p.After)
+					}
+				}
 				reverse = append(reverse, &schema.DropColumn{C: change.C})
 			case *schema.ModifyColumn:
 				if err := checkChangeGenerated(change.From, change.To); err != nil {
@@ -485,6 +497,10 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 		return fmt.Errorf("format type for column %q: %w", c.Name, err)
 	}
 	b.Ident(c.Name).P(typ)
+	// In MariaDB, the COMPRESSED attribute is part of the "data_type" stage.
+	if sqlx.Has(c.Attrs, &Compressed{}) && s.Maria() {
+		b.P("COMPRESSED")
+	}
 	if cs := (schema.Charset{}); sqlx.Has(c.Attrs, &cs) {
 		if !supportsCharset(c.Type.Type) {
 			return fmt.Errorf("column %q of type %T does not support the CHARSET attribute", c.Name, c.Type.Type)
@@ -539,6 +555,24 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 			if a.V > 0 && !sqlx.Has(t.Attrs, &AutoIncrement{}) {
 				t.Attrs = append(t.Attrs, a)
 			}
+		case *SRID:
+			b.P("SRID", strconv.Itoa(a.ID))
+		case *Compressed:
+			switch {
+			case !s.SupportsCompressedColumns():
+				return fmt.Errorf("column %q: compressed columns are supported only by MariaDB and Percona Server", c.Name)
+			case s.Percona():
+				b.P("COLUMN_FORMAT COMPRESSED")
+			}
+		case *AutoRandom:
+			switch {
+			case !s.TiDB():
+				return fmt.Errorf("column %q: AUTO_RANDOM is supported only by TiDB", c.Name)
+			case a.ShardBits > 0:
+				b.P(fmt.Sprintf("AUTO_RANDOM(%d)", a.ShardBits))
+			default:
+				b.P("AUTO_RANDOM")
+			}
 		default:
 			s.attr(b, a)
 		}
@@ -644,6 +678,24 @@ func (s *state) tableAttr(b *sqlx.Builder, c schema.Change, attrs ...schema.Attr
 			b.P("COLLATE", a.V)
 		case *schema.Comment:
 			b.P("COMMENT", quote(a.Text))
+		case *Encryption:
+			b.P("ENCRYPTION", quote(yesNo(a.V)))
+		case *Compression:
+			b.P("COMPRESSION", quote(a.V))
+		case *InsertMethod:
+			b.P("INSERT_METHOD", a.V)
+		case *Union:
+			b.P("UNION").Wrap(func(b *sqlx.Builder) {
+				b.MapComma(a.T, func(i int, b *sqlx.Builder) {
+					b.Ident(a.T[i])
+				})
+			})
+		case *StatsPersistent, *StatsAutoRecalc, *StatsSamplePages:
+			name, v, _ := statsOption(a)
+			b.P(strings.ToUpper(name), v)
+		case *AvgRowLength, *MaxRows, *MinRows:
+			name, v, _ := rowsOption(a)
+			b.P(strings.ToUpper(name), strconv.FormatUint(v, 10))
 		}
 	}
 }
@@ -685,6 +737,10 @@ func (*state) attr(b *sqlx.Builder, attrs ...schema.Attr) {
 
 // columnDefault writes the default value of column to the builder.
 func (s *state) columnDefault(b *sqlx.Builder, c *schema.Column) {
+	if nullDefault(c) {
+		b.P("DEFAULT NULL")
+		return
+	}
 	switch x := c.Default.(type) {
 	case *schema.Literal:
 		v := x.V
@@ -699,7 +755,7 @@ func (s *state) columnDefault(b *sqlx.Builder, c *schema.Column) {
 		switch t := c.Type.Type; {
 		case isHex(v), hasNumericDefault(t), strings.HasPrefix(v, "(") && strings.HasSuffix(v, ")"):
 		default:
-			if _, ok := t.(*schema.TimeType); !ok || !strings.HasPrefix(strings.ToLower(v), currentTS) {
+			if _, ok := t.(*schema.TimeType); !ok || !strings.HasPrefix(strings.ToLower(v), currentTS) && !reCurrTimestampP.MatchString(v) {
 				v = quote(v)
 			}
 		}
@@ -779,6 +835,14 @@ func checkChangeGenerated(from, to *schema.Column) error {
 	return nil
 }
 
+// yesNo returns the 'Y' or 'N' value of table options.
+func yesNo(b bool) string {
+	if b {
+		return "Y"
+	}
+	return "N"
+}
+
 func quote(s string) string {
 	if sqlx.IsQuoted(s, '"', '\'') {
 		return s
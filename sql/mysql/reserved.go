@@ -0,0 +1,60 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// reservedWords holds the list of MySQL 8.0 reserved words.
+// See: https://dev.mysql.com/doc/refman/8.0/en/keywords.html.
+//
+//go:embed reserved.txt
+var reservedWords string
+
+var (
+	reservedOnce sync.Once
+	reserved     map[string]bool
+)
+
+// IsReserved reports if the given identifier is a MySQL reserved word.
+func IsReserved(name string) bool {
+	reservedOnce.Do(func() {
+		words := strings.Fields(reservedWords)
+		reserved = make(map[string]bool, len(words))
+		for _, w := range words {
+			reserved[w] = true
+		}
+	})
+	return reserved[strings.ToLower(name)]
+}
+
+// ReservedNames returns the names of the tables, columns and indexes in the given
+// schema that are MySQL reserved words. Such names must be quoted in SQL statements,
+// and tools that generate unquoted identifiers are expected to fail on them.
+func ReservedNames(s *schema.Schema) []string {
+	var names []string
+	for _, t := range s.Tables {
+		if IsReserved(t.Name) {
+			names = append(names, fmt.Sprintf("table %q", t.Name))
+		}
+		for _, c := range t.Columns {
+			if IsReserved(c.Name) {
+				names = append(names, fmt.Sprintf("column %q.%q", t.Name, c.Name))
+			}
+		}
+		for _, idx := range t.Indexes {
+			if IsReserved(idx.Name) {
+				names = append(names, fmt.Sprintf("index %q.%q", t.Name, idx.Name))
+			}
+		}
+	}
+	return names
+}
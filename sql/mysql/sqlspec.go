@@ -5,18 +5,29 @@
 package mysql
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/internal/specutil"
 	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/mysql/internal/mysqlversion"
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlspec"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -27,16 +38,50 @@ type doc struct {
 
 // evalSpec evaluates an Atlas DDL document into v using the input.
 func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
+	return evalSpecContext(context.Background(), p, v, input)
+}
+
+// evalSpecContext is like evalSpec, but allows cancelling the
+// conversion of the document to its schema representation.
+func evalSpecContext(ctx context.Context, p *hclparse.Parser, v any, input map[string]cty.Value) error {
+	return evalDoc(ctx, hclState, p, v, input, scanDoc)
+}
+
+// scanDoc converts the document tables and schemas to the given realm.
+func scanDoc(ctx context.Context, r *schema.Realm, d *doc) error {
+	return specutil.ScanContext(ctx, r, d.Schemas, d.Tables, convertTable)
+}
+
+// evalDoc evaluates an Atlas DDL document into v using the state and the
+// input, and the given scan function to convert the document to a realm.
+func evalDoc(ctx context.Context, state *schemahcl.State, p *hclparse.Parser, v any, input map[string]cty.Value, scan func(context.Context, *schema.Realm, *doc) error) error {
+	return decodeDoc(ctx, v, func(d any) error {
+		return state.Eval(p, d, input)
+	}, scan)
+}
+
+// decodeDoc decodes an Atlas DDL document into v using the given decode
+// function, and the given scan function to convert the document to a realm.
+func decodeDoc(ctx context.Context, v any, decode func(any) error, scan func(context.Context, *schema.Realm, *doc) error) error {
 	switch v := v.(type) {
 	case *schema.Realm:
 		var d doc
-		if err := hclState.Eval(p, &d, input); err != nil {
+		if err := decode(&d); err != nil {
+			return err
+		}
+		if err := checkSchemaNames(d.Schemas); err != nil {
 			return err
 		}
-		err := specutil.Scan(v, d.Schemas, d.Tables, convertTable)
+		err := scan(ctx, v, &d)
 		if err != nil {
 			return fmt.Errorf("mysql: failed converting to *schema.Realm: %w", err)
 		}
+		if err := checkSetNull(v); err != nil {
+			return fmt.Errorf("mysql: failed converting to *schema.Realm: %w", err)
+		}
+		if err := checkIdentLen(v); err != nil {
+			return fmt.Errorf("mysql: failed converting to *schema.Realm: %w", err)
+		}
 		for _, schemaSpec := range d.Schemas {
 			schm, ok := v.Schema(schemaSpec.Name)
 			if !ok {
@@ -48,14 +93,23 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 		}
 	case *schema.Schema:
 		var d doc
-		if err := hclState.Eval(p, &d, input); err != nil {
+		if err := decode(&d); err != nil {
 			return err
 		}
 		if len(d.Schemas) != 1 {
 			return fmt.Errorf("mysql: expecting document to contain a single schema, got %d", len(d.Schemas))
 		}
+		if err := checkSchemaNames(d.Schemas); err != nil {
+			return err
+		}
 		var r schema.Realm
-		if err := specutil.Scan(&r, d.Schemas, d.Tables, convertTable); err != nil {
+		if err := scan(ctx, &r, &d); err != nil {
+			return err
+		}
+		if err := checkSetNull(&r); err != nil {
+			return err
+		}
+		if err := checkIdentLen(&r); err != nil {
 			return err
 		}
 		if err := convertCharset(d.Schemas[0], &r.Schemas[0].Attrs); err != nil {
@@ -66,7 +120,93 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 	case schema.Schema, schema.Realm:
 		return fmt.Errorf("mysql: Eval expects a pointer: received %[1]T, expected *%[1]T", v)
 	default:
-		return hclState.Eval(p, v, input)
+		return decode(v)
+	}
+	return nil
+}
+
+// maxIdentLen is the maximum length of database, table and column names in MySQL.
+const maxIdentLen = 64
+
+// checkSchemaNames checks that the schema names defined in the document are valid
+// MySQL database names, as invalid names fail only when they are applied.
+func checkSchemaNames(schemas []*sqlspec.Schema) error {
+	for _, s := range schemas {
+		switch name := s.Name; {
+		case name == "":
+			return errors.New("mysql: schema name cannot be empty")
+		case utf8.RuneCountInString(name) > maxIdentLen:
+			return fmt.Errorf("mysql: schema name %q is too long (%d characters), maximum is %d", name, utf8.RuneCountInString(name), maxIdentLen)
+		case strings.HasSuffix(name, " "):
+			return fmt.Errorf("mysql: schema name %q cannot end with a space", name)
+		case strings.ContainsRune(name, 0):
+			return fmt.Errorf("mysql: schema name %q cannot contain NUL characters", name)
+		}
+	}
+	return nil
+}
+
+// checkSetNull checks that foreign keys with the SET NULL referential action
+// are not defined on non-nullable columns, as MySQL rejects such definitions.
+func checkSetNull(r *schema.Realm) error {
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			for _, fk := range t.ForeignKeys {
+				if fk.OnDelete != schema.SetNull && fk.OnUpdate != schema.SetNull {
+					continue
+				}
+				for _, c := range fk.Columns {
+					if c.Type == nil || !c.Type.Null {
+						return fmt.Errorf("foreign key %q of table %q uses SET NULL, but column %q is not nullable", fk.Symbol, t.Name, c.Name)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkIdentLen checks that the names of the tables, columns, indexes, foreign keys
+// and checks do not exceed the maximum identifier length in MySQL, as long names
+// fail only when they are applied.
+func checkIdentLen(r *schema.Realm) error {
+	check := func(kind, name, table string) error {
+		if n := utf8.RuneCountInString(name); n > maxIdentLen {
+			if table == "" {
+				return fmt.Errorf("%s name %q is too long (%d characters), maximum is %d", kind, name, n, maxIdentLen)
+			}
+			return fmt.Errorf("%s name %q of table %q is too long (%d characters), maximum is %d", kind, name, table, n, maxIdentLen)
+		}
+		return nil
+	}
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			if err := check("table", t.Name, ""); err != nil {
+				return err
+			}
+			for _, c := range t.Columns {
+				if err := check("column", c.Name, t.Name); err != nil {
+					return err
+				}
+			}
+			for _, idx := range t.Indexes {
+				if err := check("index", idx.Name, t.Name); err != nil {
+					return err
+				}
+			}
+			for _, fk := range t.ForeignKeys {
+				if err := check("foreign key", fk.Symbol, t.Name); err != nil {
+					return err
+				}
+			}
+			for _, a := range t.Attrs {
+				if c, ok := a.(*schema.Check); ok {
+					if err := check("check", c.Name, t.Name); err != nil {
+						return err
+					}
+				}
+			}
+		}
 	}
 	return nil
 }
@@ -76,17 +216,32 @@ func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 	return specutil.Marshal(v, marshaler, schemaSpec)
 }
 
+// MarshalSpecTo is like MarshalSpec, but writes the Atlas DDL document to w. Note that
+// the document is still converted to its spec as a whole, and only the encoded output of
+// its tables and schemas is written one by one.
+func MarshalSpecTo(w io.Writer, v any, marshaler schemahcl.Marshaler) error {
+	// Marshalers of this package are unwrapped to their state and spec
+	// function, as chunks are converted to specs before they are encoded.
+	if m, ok := marshaler.(*hclMarshaler); ok {
+		return specutil.MarshalTo(w, v, m.state, m.spec)
+	}
+	return specutil.MarshalTo(w, v, marshaler, schemaSpec)
+}
+
 var (
-	hclState = schemahcl.New(
-		schemahcl.WithTypes(TypeRegistry.Specs()),
-		schemahcl.WithScopedEnums("table.index.type", IndexTypeBTree, IndexTypeHash, IndexTypeFullText, IndexTypeSpatial),
-		schemahcl.WithScopedEnums("table.column.as.type", stored, persistent, virtual),
-		schemahcl.WithScopedEnums("table.foreign_key.on_update", specutil.ReferenceVars...),
-		schemahcl.WithScopedEnums("table.foreign_key.on_delete", specutil.ReferenceVars...),
-	)
+	hclState = schemahcl.New(hclOptions()...)
 	// MarshalHCL marshals v into an Atlas HCL DDL document.
-	MarshalHCL = schemahcl.MarshalerFunc(func(v any) ([]byte, error) {
-		return MarshalSpec(v, hclState)
+	MarshalHCL schemahcl.Marshaler = &hclMarshaler{state: hclState, spec: schemaSpec}
+	// MarshalHCLSerial works like MarshalHCL, but collapses the columns that match
+	// the expansion of the SERIAL pseudo-type back into "serial".
+	MarshalHCLSerial = schemahcl.MarshalerFunc(func(v any) ([]byte, error) {
+		return specutil.Marshal(v, hclState, func(s *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error) {
+			return schemaSpecWith(s, serialTableSpec)
+		})
+	})
+	// MarshalJSON marshals v into the JSON representation of an Atlas HCL DDL document.
+	MarshalJSON = schemahcl.MarshalerFunc(func(v any) ([]byte, error) {
+		return MarshalSpec(v, schemahcl.MarshalerFunc(hclState.MarshalSpecJSON))
 	})
 	// EvalHCL implements the schemahcl.Evaluator interface.
 	EvalHCL = schemahcl.EvalFunc(evalSpec)
@@ -96,32 +251,874 @@ var (
 	EvalHCLBytes = specutil.HCLBytesFunc(EvalHCL)
 )
 
-// convertTable converts a sqlspec.Table to a schema.Table. Table conversion is done without converting
-// ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
-// at this point. Instead, the linking is done by the convertSchema function.
-func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, error) {
-	t, err := specutil.Table(spec, parent, convertColumn, specutil.PrimaryKey, convertIndex, convertCheck)
-	if err != nil {
-		return nil, err
+// scopedEnums holds the enums that are allowed in the different paths of the document.
+var scopedEnums = map[string][]string{
+	"table.index.type":            {IndexTypeBTree, IndexTypeHash, IndexTypeFullText, IndexTypeSpatial},
+	"table.column.as.type":        {stored, persistent, virtual},
+	"table.foreign_key.on_update": specutil.ReferenceVars,
+	"table.foreign_key.on_delete": specutil.ReferenceVars,
+	"table.migration.algorithm":   {"DEFAULT", "INSTANT", "INPLACE", "COPY"},
+	"table.migration.lock":        {"DEFAULT", "NONE", "SHARED", "EXCLUSIVE"},
+}
+
+// ScopedEnums returns the enum values that are allowed in the different paths
+// of the MySQL HCL document, keyed by their path. For example:
+//
+//	ScopedEnums()["table.index.type"]	// [BTREE HASH FULLTEXT SPATIAL]
+func ScopedEnums() map[string][]string {
+	enums := make(map[string][]string, len(scopedEnums))
+	for p, vs := range scopedEnums {
+		enums[p] = append([]string(nil), vs...)
+	}
+	return enums
+}
+
+// hclOptions returns the schemahcl options used by the MySQL HCL state.
+func hclOptions() []schemahcl.Option {
+	opts := []schemahcl.Option{
+		schemahcl.WithTypes(TypeRegistry.Specs()),
+	}
+	for p, vs := range scopedEnums {
+		opts = append(opts, schemahcl.WithScopedEnums(p, vs...))
+	}
+	return opts
+}
+
+// MarshalHCLWith returns a marshaler that works like MarshalHCL, but configured
+// with additional schemahcl options. For example:
+//
+//	MarshalHCLWith(schemahcl.WithHeader("Generated by Atlas; do not edit")).MarshalSpec(s)
+func MarshalHCLWith(opts ...schemahcl.Option) schemahcl.Marshaler {
+	m := &hclMarshaler{
+		state: schemahcl.New(append(hclOptions(), opts...)...),
+		spec:  schemaSpec,
+	}
+	if fo, ok := m.state.Value(formatOptionsKey{}).([]FormatOption); ok {
+		r := NewTypeRegistry(fo...)
+		m.spec = func(s *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error) {
+			return schemaSpecWith(s, func(t *schema.Table) (*sqlspec.Table, error) {
+				return tableSpecWith(t, r)
+			})
+		}
+	}
+	return m
+}
+
+// hclMarshaler marshals schema elements into Atlas HCL documents using its state.
+type hclMarshaler struct {
+	state *schemahcl.State
+	spec  func(*schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error)
+}
+
+// MarshalSpec implements schemahcl.Marshaler.
+func (m *hclMarshaler) MarshalSpec(v any) ([]byte, error) {
+	return specutil.Marshal(v, m.state, m.spec)
+}
+
+// formatOptionsKey is the schemahcl.WithValue key of the format options.
+type formatOptionsKey struct{}
+
+// WithFormatOptions returns a schemahcl option for MarshalHCLWith that formats the
+// column types using the given options. For example, the following omits the default
+// sizes of the types (e.g. "decimal" instead of "decimal(10)"):
+//
+//	MarshalHCLWith(WithFormatOptions(OmitDefaults())).MarshalSpec(s)
+func WithFormatOptions(opts ...FormatOption) schemahcl.Option {
+	return schemahcl.WithValue(formatOptionsKey{}, opts)
+}
+
+// WithoutCosmetics returns a schemahcl option for MarshalHCLWith that omits the
+// cosmetic attributes (comments, charsets and collations) from the marshaled
+// document. It is useful for comparing the structure of two schemas, as schemas
+// that differ only cosmetically are marshaled identically. For example:
+//
+//	MarshalHCLWith(WithoutCosmetics()).MarshalSpec(s)
+func WithoutCosmetics() schemahcl.Option {
+	return schemahcl.WithoutAttrs("comment", "charset", "collate")
+}
+
+// EvalHCLWith returns an evaluator that works like EvalHCL, but configured with
+// additional schemahcl options, or the evaluation options of this package (e.g.
+// WithStrictAttrs). Options can be combined. For example:
+//
+//	EvalHCLWith(schemahcl.WithEnvVars("ATLAS_VAR_"), WithStrictAttrs(), WithAllErrors()).Eval(p, &s, nil)
+func EvalHCLWith(opts ...schemahcl.Option) schemahcl.Evaluator {
+	state := schemahcl.New(append(hclOptions(), opts...)...)
+	conv := tableConverter{
+		strict:      state.Value(strictAttrsKey{}) == true,
+		passthrough: state.Value(unknownAttrsKey{}) == true,
+		all:         state.Value(allErrorsKey{}) == true,
+	}
+	normalize, _ := state.Value(normalizeNamesKey{}).(func(string) string)
+	return schemahcl.EvalFunc(func(p *hclparse.Parser, v any, input map[string]cty.Value) error {
+		if err := evalDoc(context.Background(), state, p, v, input, conv.scan); err != nil || normalize == nil {
+			return err
+		}
+		switch v := v.(type) {
+		case *schema.Realm:
+			return normalizeNames(v.Schemas, normalize)
+		case *schema.Schema:
+			return normalizeNames([]*schema.Schema{v}, normalize)
+		}
+		return nil
+	})
+}
+
+// The keys of the evaluation options of this package.
+type (
+	strictAttrsKey    struct{}
+	unknownAttrsKey   struct{}
+	allErrorsKey      struct{}
+	normalizeNamesKey struct{}
+)
+
+// WithStrictAttrs returns a schemahcl option for EvalHCLWith that fails the evaluation in
+// case a table, column, index or check block contains attributes that are not recognized
+// by the driver, instead of ignoring them. It allows catching typos, such as "nul = true"
+// for "null = true".
+func WithStrictAttrs() schemahcl.Option {
+	return schemahcl.WithValue(strictAttrsKey{}, true)
+}
+
+// WithUnknownAttrs returns a schemahcl option for EvalHCLWith that preserves table and column
+// attributes that are not recognized by the driver as UnknownAttr, instead of dropping them.
+// MarshalHCL emits these attributes as-is, allowing forward-compatible table options to survive
+// a round-trip.
+func WithUnknownAttrs() schemahcl.Option {
+	return schemahcl.WithValue(unknownAttrsKey{}, true)
+}
+
+// WithAllErrors returns a schemahcl option for EvalHCLWith that, instead of returning on the
+// first conversion error, converts the entire document and returns all errors at once. The
+// returned error implements the interface below, and allows iterating over the errors of all
+// tables, columns, indexes and attributes.
+//
+//	interface {
+//		Errors() []error
+//	}
+func WithAllErrors() schemahcl.Option {
+	return schemahcl.WithValue(allErrorsKey{}, true)
+}
+
+// WithNormalizedNames returns a schemahcl option for EvalHCLWith that normalizes the names
+// of the evaluated schemas and tables using the given function. For example, servers configured
+// with lower_case_table_names=1 store these names in lowercase, and strings.ToLower can be used
+// to match their inspection:
+//
+//	EvalHCLWith(WithNormalizedNames(strings.ToLower)).Eval(p, &s, nil)
+//
+// Note that column, index and foreign-key constraint names are not normalized, as they are not
+// affected by lower_case_table_names. References to tables (e.g. the "ref_columns" of foreign keys)
+// point to the table objects themselves, and therefore follow their normalized names.
+func WithNormalizedNames(normalize func(string) string) schemahcl.Option {
+	return schemahcl.WithValue(normalizeNamesKey{}, normalize)
+}
+
+// normalizeNames normalizes the names of the given schemas and their tables,
+// and reports an error if two of them end up with the same name.
+func normalizeNames(schemas []*schema.Schema, normalize func(string) string) error {
+	names := make(map[string]string, len(schemas))
+	for _, s := range schemas {
+		name := normalize(s.Name)
+		if prev, ok := names[name]; ok {
+			return fmt.Errorf("mysql: schemas %q and %q have the same normalized name %q", prev, s.Name, name)
+		}
+		names[name], s.Name = s.Name, name
+		tables := make(map[string]string, len(s.Tables))
+		for _, t := range s.Tables {
+			name := normalize(t.Name)
+			if prev, ok := tables[name]; ok {
+				return fmt.Errorf("mysql: tables %q and %q in schema %q have the same normalized name %q", prev, t.Name, s.Name, name)
+			}
+			tables[name], t.Name = t.Name, name
+		}
+	}
+	return nil
+}
+
+// EvalHCLContext is like EvalHCLBytes, but stops evaluating the document
+// and returns the context error in case the given context is canceled.
+func EvalHCLContext(ctx context.Context, data []byte, v any, input map[string]cty.Value) error {
+	parser := hclparse.NewParser()
+	if _, diag := parser.ParseHCL(data, ""); diag.HasErrors() {
+		return diag
+	}
+	return evalSpecContext(ctx, parser, v, input)
+}
+
+// UnmarshalHCLReader is like EvalHCLBytes, but reads the HCL document from the given
+// reader. The document is read entirely before it is evaluated, as HCL parsing requires
+// the whole document.
+func UnmarshalHCLReader(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("mysql: reading HCL document: %w", err)
+	}
+	return EvalHCLBytes(data, v, nil)
+}
+
+// UnmarshalJSON decodes the JSON representation of an Atlas HCL DDL document, as
+// returned by MarshalJSON, into v. The document is converted like HCL documents.
+func UnmarshalJSON(data []byte, v any) error {
+	return decodeDoc(context.Background(), v, func(d any) error {
+		return hclState.EvalJSON(data, d)
+	}, scanDoc)
+}
+
+// EvalHCLFiles evaluates the given HCL documents, keyed by their file names, as
+// a single Atlas DDL document into v. Documents may reference blocks defined in
+// other documents, and syntax errors are reported with the originating file name.
+func EvalHCLFiles(files map[string][]byte, v any) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parser := hclparse.NewParser()
+	for _, name := range names {
+		if _, diag := parser.ParseHCL(files[name], name); diag.HasErrors() {
+			return diag
+		}
+	}
+	return evalSpec(parser, v, nil)
+}
+
+// NormalizeHCL returns the canonical form of the given Atlas HCL document. The document
+// is evaluated to a schema.Realm and marshaled back using MarshalHCL. Hence, comparing a
+// document with its normalized form reports if the document is already normalized.
+func NormalizeHCL(data []byte) ([]byte, error) {
+	var r schema.Realm
+	if err := EvalHCLBytes(data, &r, nil); err != nil {
+		return nil, err
+	}
+	return MarshalHCL.MarshalSpec(&r)
+}
+
+// SchemaNames returns the names of the schemas declared in the given Atlas HCL
+// document, in their declaration order. Unlike EvalHCLBytes, the document tables
+// are not converted, and therefore, it is a lightweight pre-flight check that can
+// be used, for example, to create the databases before applying the document.
+func SchemaNames(data []byte) ([]string, error) {
+	p := hclparse.NewParser()
+	if _, diag := p.ParseHCL(data, ""); diag.HasErrors() {
+		return nil, diag
+	}
+	var d doc
+	if err := hclState.Eval(p, &d, nil); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(d.Schemas))
+	for i, s := range d.Schemas {
+		names[i] = s.Name
+	}
+	return names, nil
+}
+
+// SchemaHash returns a stable SHA-256 hash (hex encoded) of the given schema, that can be
+// stored to detect schema changes cheaply. The schema is marshaled to its canonical HCL
+// form, with its tables, indexes, foreign keys and checks sorted by name and without
+// cosmetic attributes. Hence, the hash does not depend on the order they were defined.
+// Columns are kept in their order, as it is part of the table definition in MySQL.
+func SchemaHash(s *schema.Schema) (string, error) {
+	sorted := *s
+	sorted.Realm = nil
+	sorted.Tables = make([]*schema.Table, len(s.Tables))
+	for i, t := range s.Tables {
+		st := *t
+		st.Indexes = append([]*schema.Index(nil), t.Indexes...)
+		sort.Slice(st.Indexes, func(i, j int) bool { return st.Indexes[i].Name < st.Indexes[j].Name })
+		st.ForeignKeys = append([]*schema.ForeignKey(nil), t.ForeignKeys...)
+		sort.Slice(st.ForeignKeys, func(i, j int) bool { return st.ForeignKeys[i].Symbol < st.ForeignKeys[j].Symbol })
+		var checks []*schema.Check
+		st.Attrs = make([]schema.Attr, 0, len(t.Attrs))
+		for _, a := range t.Attrs {
+			if c, ok := a.(*schema.Check); ok {
+				checks = append(checks, c)
+			} else {
+				st.Attrs = append(st.Attrs, a)
+			}
+		}
+		sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+		for _, c := range checks {
+			st.Attrs = append(st.Attrs, c)
+		}
+		sorted.Tables[i] = &st
+	}
+	sort.Slice(sorted.Tables, func(i, j int) bool { return sorted.Tables[i].Name < sorted.Tables[j].Name })
+	buf, err := MarshalHCLWith(WithoutCosmetics()).MarshalSpec(&sorted)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(buf)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// UnknownAttr holds a table or column attribute that is not recognized
+// by the driver, and is preserved as-is by the WithUnknownAttrs option.
+type UnknownAttr struct {
+	schema.Attr
+	A *schemahcl.Attr
+}
+
+// IndexHint holds a free-form "hint" block defined on an index. The block
+// is not interpreted by Atlas and is ignored when diffing indexes. It allows
+// annotating indexes (e.g. with optimizer hints) for external tooling.
+type IndexHint struct {
+	schema.Attr
+	R *schemahcl.Resource
+}
+
+// MigrationHints holds the preferred ALGORITHM and LOCK clauses for altering
+// the table, as defined in its "migration" block. The hints are stored as table
+// metadata and are ignored when diffing tables. For example:
+//
+//	table "users" {
+//		...
+//		migration {
+//			algorithm = INPLACE
+//			lock      = NONE
+//		}
+//	}
+type MigrationHints struct {
+	schema.Attr
+	Algorithm string // DEFAULT, INSTANT, INPLACE or COPY.
+	Lock      string // DEFAULT, NONE, SHARED or EXCLUSIVE.
+}
+
+// The attributes that are recognized by the driver, in addition to the ones
+// defined on the sqlspec structs (e.g. "null" and "type" for columns).
+var (
+	tableAttrs = map[string]bool{
+		"charset": true, "collate": true, "collation": true, "comment": true,
+		"auto_increment": true, "temporary": true, "encryption": true,
+		"stats_persistent": true, "stats_auto_recalc": true, "stats_sample_pages": true,
+		"avg_row_length": true, "max_rows": true, "min_rows": true, "union": true,
+		"insert_method": true, "compression": true,
+	}
+	columnAttrs = map[string]bool{
+		"charset": true, "collate": true, "collation": true, "comment": true,
+		"on_update": true, "auto_increment": true, "auto_random": true, "srid": true,
+		"first": true, "after": true, "as": true, "unsigned": true, "zerofill": true,
+		"compressed": true,
+	}
+	indexAttrs     = map[string]bool{"type": true, "comment": true}
+	indexPartAttrs = map[string]bool{"prefix": true}
+	checkAttrs     = map[string]bool{"enforced": true, "comment": true}
+)
+
+// Diagnostic describes a problem in an Atlas HCL document.
+type Diagnostic struct {
+	Severity string     // "error" or "warning"
+	Summary  string     // short description of the problem
+	Detail   string     // detailed description, if any
+	Range    *hcl.Range // source range of the problem, nil if unknown
+	Path     []string   // enclosing blocks path, e.g. [table users column id]
+}
+
+// EvalHCLDiagnostics is like EvalHCLBytes, but also returns the problems in the document
+// as structured diagnostics, allowing editors to underline the exact source range of each
+// problem. Errors that are not tied to a source range (e.g. schema validation errors) are
+// returned as a single diagnostic without a range.
+func EvalHCLDiagnostics(data []byte, v any) ([]Diagnostic, error) {
+	parser := hclparse.NewParser()
+	f, diags := parser.ParseHCL(data, "")
+	if diags.HasErrors() {
+		return toDiagnostics(f, diags), diags
+	}
+	err := evalSpec(parser, v, nil)
+	switch {
+	case err == nil:
+		return nil, nil
+	case errors.As(err, &diags):
+		return toDiagnostics(f, diags), err
+	default:
+		return []Diagnostic{{Severity: "error", Summary: err.Error()}}, err
+	}
+}
+
+// toDiagnostics converts the HCL diagnostics to Diagnostic.
+func toDiagnostics(f *hcl.File, diags hcl.Diagnostics) []Diagnostic {
+	ds := make([]Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		dg := Diagnostic{Severity: "error", Summary: d.Summary, Detail: d.Detail, Range: d.Subject}
+		if d.Severity == hcl.DiagWarning {
+			dg.Severity = "warning"
+		}
+		if f != nil && d.Subject != nil {
+			if body, ok := f.Body.(*hclsyntax.Body); ok {
+				dg.Path = blockPath(body, d.Subject.Start)
+			}
+		}
+		ds = append(ds, dg)
+	}
+	return ds
+}
+
+// blockPath returns the types and labels of the blocks that contain the given position.
+func blockPath(body *hclsyntax.Body, pos hcl.Pos) []string {
+	for _, b := range body.Blocks {
+		if r := b.Range(); r.ContainsOffset(pos.Byte) {
+			return append(append([]string{b.Type}, b.Labels...), blockPath(b.Body, pos)...)
+		}
+	}
+	return nil
+}
+
+// tableConverter converts sqlspec.Tables to schema.Tables,
+// in the mode configured by the evaluation options.
+type tableConverter struct {
+	strict      bool // fail on attributes that are not recognized by the driver
+	passthrough bool // store the unrecognized attributes as UnknownAttr
+	all         bool // accumulate all conversion errors, instead of returning on the first
+}
+
+// scan converts the document tables and schemas to the given realm.
+func (c tableConverter) scan(ctx context.Context, r *schema.Realm, d *doc) error {
+	if c.all {
+		return specutil.ScanAll(ctx, r, d.Schemas, d.Tables, c.convert)
+	}
+	return specutil.ScanContext(ctx, r, d.Schemas, d.Tables, c.convert)
+}
+
+// convert converts a sqlspec.Table to a schema.Table. In case all errors are
+// accumulated, the returned error is a specutil.Errors list, and each of its
+// errors is prefixed with the table name.
+func (c tableConverter) convert(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, error) {
+	var errs specutil.Errors
+	if c.strict {
+		for _, err := range unknownAttrs(spec) {
+			if !c.all {
+				return nil, err
+			}
+			errs = append(errs, err)
+		}
+	}
+	var t *schema.Table
+	if c.all {
+		var tErrs specutil.Errors
+		t, tErrs = specutil.TableAll(spec, parent, convertColumn, convertPrimaryKey, convertIndex, convertCheck)
+		for _, err := range tErrs {
+			errs = append(errs, fmt.Errorf("table %q: %w", spec.Name, err))
+		}
+	} else {
+		var err error
+		if t, err = specutil.Table(spec, parent, convertColumn, convertPrimaryKey, convertIndex, convertCheck); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range tableAttrFuncs {
+		if err := f(spec, t); err != nil {
+			if !c.all {
+				return nil, err
+			}
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if c.passthrough {
+		passthroughAttrs(spec, t)
+	}
+	return t, nil
+}
+
+// unknownAttrs returns an error for each table, column, index
+// or check attribute that is not recognized by the driver.
+func unknownAttrs(spec *sqlspec.Table) (errs []error) {
+	for _, err := range knownAttrs(spec.Extra.Attrs, tableAttrs) {
+		errs = append(errs, fmt.Errorf("table %q: %w", spec.Name, err))
+	}
+	for _, c := range spec.Columns {
+		for _, err := range knownAttrs(c.Extra.Attrs, columnAttrs) {
+			errs = append(errs, fmt.Errorf("table %q: column %q: %w", spec.Name, c.Name, err))
+		}
+	}
+	for _, idx := range spec.Indexes {
+		for _, err := range knownAttrs(idx.Extra.Attrs, indexAttrs) {
+			errs = append(errs, fmt.Errorf("table %q: index %q: %w", spec.Name, idx.Name, err))
+		}
+		for i, p := range idx.Parts {
+			for _, err := range knownAttrs(p.Extra.Attrs, indexPartAttrs) {
+				errs = append(errs, fmt.Errorf("table %q: index %q: part %d: %w", spec.Name, idx.Name, i, err))
+			}
+		}
+	}
+	for _, c := range spec.Checks {
+		for _, err := range knownAttrs(c.Extra.Attrs, checkAttrs) {
+			errs = append(errs, fmt.Errorf("table %q: check %q: %w", spec.Name, c.Name, err))
+		}
+	}
+	return errs
+}
+
+// knownAttrs returns an error for each attribute that is not in the known set.
+func knownAttrs(attrs []*schemahcl.Attr, known map[string]bool) (errs []error) {
+	for _, a := range attrs {
+		if !known[a.K] {
+			errs = append(errs, fmt.Errorf("unknown attribute %q", a.K))
+		}
+	}
+	return errs
+}
+
+// passthroughAttrs stores the unrecognized table and column attributes as UnknownAttr.
+func passthroughAttrs(spec *sqlspec.Table, t *schema.Table) {
+	for _, a := range spec.Extra.Attrs {
+		if !tableAttrs[a.K] {
+			t.AddAttrs(&UnknownAttr{A: a})
+		}
+	}
+	for _, cs := range spec.Columns {
+		c, ok := t.Column(cs.Name)
+		if !ok {
+			continue
+		}
+		for _, a := range cs.Extra.Attrs {
+			if !columnAttrs[a.K] {
+				c.AddAttrs(&UnknownAttr{A: a})
+			}
+		}
+	}
+}
+
+// convertMigrationHints converts the "migration" block of a table into MigrationHints.
+func convertMigrationHints(r *schemahcl.Resource) (*MigrationHints, error) {
+	var spec struct {
+		Algorithm string `spec:"algorithm"`
+		Lock      string `spec:"lock"`
+	}
+	if err := r.As(&spec); err != nil {
+		return nil, err
+	}
+	h := &MigrationHints{Algorithm: strings.ToUpper(spec.Algorithm), Lock: strings.ToUpper(spec.Lock)}
+	if vs := scopedEnums["table.migration.algorithm"]; h.Algorithm != "" && !containsAll(vs, []string{h.Algorithm}) {
+		return nil, fmt.Errorf("invalid migration algorithm %q, expected one of: %s", spec.Algorithm, strings.Join(vs, ", "))
+	}
+	if vs := scopedEnums["table.migration.lock"]; h.Lock != "" && !containsAll(vs, []string{h.Lock}) {
+		return nil, fmt.Errorf("invalid migration lock %q, expected one of: %s", spec.Lock, strings.Join(vs, ", "))
+	}
+	return h, nil
+}
+
+// convertTable converts a sqlspec.Table to a schema.Table. Table conversion is done without converting
+// ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
+// at this point. Instead, the linking is done by the convertSchema function.
+func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, error) {
+	return tableConverter{}.convert(spec, parent)
+}
+
+// tableAttrFuncs convert the table-level attributes of the spec, and validate the table
+// after its columns, indexes and checks were converted. Each function returns on its first
+// error, and its errors are prefixed with the table name.
+var tableAttrFuncs = []func(*sqlspec.Table, *schema.Table) error{
+	func(_ *sqlspec.Table, t *schema.Table) error {
+		return checkGeneratedRefs(t)
+	},
+	func(spec *sqlspec.Table, t *schema.Table) error {
+		if err := convertCharset(spec, &t.Attrs); err != nil {
+			return fmt.Errorf("table %q: %w", spec.Name, err)
+		}
+		return nil
+	},
+	serialIndexes,
+	// MySQL allows setting the initial AUTO_INCREMENT value
+	// on the table definition.
+	func(spec *sqlspec.Table, t *schema.Table) error {
+		if attr, ok := spec.Attr("auto_increment"); ok {
+			v, err := attr.Int64()
+			if err != nil {
+				return fmt.Errorf("table %q: %w", spec.Name, err)
+			}
+			t.AddAttrs(&AutoIncrement{V: v})
+		}
+		return nil
+	},
+	func(spec *sqlspec.Table, t *schema.Table) error {
+		attr, ok := spec.Attr("encryption")
+		if !ok {
+			return nil
+		}
+		v, err := attr.String()
+		if err != nil {
+			return fmt.Errorf("table %q: %w", spec.Name, err)
+		}
+		switch strings.ToUpper(v) {
+		case "Y":
+			t.AddAttrs(&Encryption{V: true})
+		case "N":
+			t.AddAttrs(&Encryption{V: false})
+		default:
+			return fmt.Errorf("table %q: invalid encryption value %q, expected \"Y\" or \"N\"", spec.Name, v)
+		}
+		return nil
+	},
+	func(spec *sqlspec.Table, t *schema.Table) error {
+		attr, ok := spec.Attr("compression")
+		if !ok {
+			return nil
+		}
+		v, err := attr.String()
+		if err != nil {
+			return fmt.Errorf("table %q: %w", spec.Name, err)
+		}
+		switch strings.ToLower(v) {
+		case "zlib", "lz4", "none":
+			t.AddAttrs(&Compression{V: v})
+		default:
+			return fmt.Errorf("table %q: invalid compression value %q, expected \"zlib\", \"lz4\" or \"none\"", spec.Name, v)
+		}
+		return nil
+	},
+	// The UNION option is meaningful only for tables using the MERGE engine.
+	func(spec *sqlspec.Table, t *schema.Table) error {
+		if attr, ok := spec.Attr("union"); ok {
+			names, err := attr.Strings()
+			if err != nil {
+				return fmt.Errorf("table %q: invalid union value: %w", spec.Name, err)
+			}
+			t.AddAttrs(&Union{T: names})
+		}
+		return nil
+	},
+	func(spec *sqlspec.Table, t *schema.Table) error {
+		attr, ok := spec.Attr("insert_method")
+		if !ok {
+			return nil
+		}
+		v, err := attr.String()
+		if err != nil {
+			return fmt.Errorf("table %q: %w", spec.Name, err)
+		}
+		switch v = strings.ToUpper(v); v {
+		case "NO", "FIRST", "LAST":
+			t.AddAttrs(&InsertMethod{V: v})
+		default:
+			return fmt.Errorf("table %q: invalid insert_method value %q, expected \"NO\", \"FIRST\" or \"LAST\"", spec.Name, v)
+		}
+		return nil
+	},
+	func(spec *sqlspec.Table, t *schema.Table) error {
+		if r, ok := spec.Remain().Resource("migration"); ok {
+			h, err := convertMigrationHints(r)
+			if err != nil {
+				return fmt.Errorf("table %q: %w", spec.Name, err)
+			}
+			t.AddAttrs(h)
+		}
+		return nil
+	},
+	func(spec *sqlspec.Table, t *schema.Table) error {
+		if attr, ok := spec.Attr("temporary"); ok {
+			b, err := attr.Bool()
+			if err != nil {
+				return fmt.Errorf("table %q: %w", spec.Name, err)
+			}
+			if b {
+				t.AddAttrs(&Temporary{})
+			}
+		}
+		return nil
+	},
+	convertStats,
+	convertRows,
+}
+
+// The InnoDB statistics table options.
+const (
+	statsPersistent  = "stats_persistent"
+	statsAutoRecalc  = "stats_auto_recalc"
+	statsSamplePages = "stats_sample_pages"
+)
+
+// convertStats converts the InnoDB statistics options of the table spec. The values
+// can be defined as numbers (e.g. stats_persistent = 1) or as "DEFAULT".
+func convertStats(spec *sqlspec.Table, t *schema.Table) error {
+	for _, name := range []string{statsPersistent, statsAutoRecalc, statsSamplePages} {
+		attr, ok := spec.Attr(name)
+		if !ok {
+			continue
+		}
+		var v string
+		switch attr.V.Type() {
+		case cty.Number:
+			n, err := attr.Int()
+			if err != nil {
+				return fmt.Errorf("table %q: %w", spec.Name, err)
+			}
+			v = strconv.Itoa(n)
+		case cty.String:
+			v = strings.ToUpper(attr.V.AsString())
+		default:
+			return fmt.Errorf("table %q: unexpected type %s for attribute %q", spec.Name, attr.V.Type().FriendlyName(), name)
+		}
+		switch n, err := strconv.Atoi(v); {
+		case v == "DEFAULT":
+		case name == statsSamplePages && (err != nil || n < 1):
+			return fmt.Errorf("table %q: invalid %s value %q, expected DEFAULT or a positive number", spec.Name, name, v)
+		case name != statsSamplePages && v != "0" && v != "1":
+			return fmt.Errorf("table %q: invalid %s value %q, expected DEFAULT, 0 or 1", spec.Name, name, v)
+		}
+		t.AddAttrs(statsAttr(name, v))
+	}
+	return nil
+}
+
+// statsAttr returns the schema attribute of the given statistics option.
+func statsAttr(name, v string) schema.Attr {
+	switch name {
+	case statsPersistent:
+		return &StatsPersistent{V: v}
+	case statsAutoRecalc:
+		return &StatsAutoRecalc{V: v}
+	default:
+		return &StatsSamplePages{V: v}
+	}
+}
+
+// statsOption returns the option name and value of the given statistics attribute.
+func statsOption(a schema.Attr) (name, v string, ok bool) {
+	switch a := a.(type) {
+	case *StatsPersistent:
+		return statsPersistent, a.V, true
+	case *StatsAutoRecalc:
+		return statsAutoRecalc, a.V, true
+	case *StatsSamplePages:
+		return statsSamplePages, a.V, true
+	}
+	return "", "", false
+}
+
+// The table options that describe the expected number and size of rows.
+const (
+	avgRowLength = "avg_row_length"
+	maxRows      = "max_rows"
+	minRows      = "min_rows"
+)
+
+// convertRows converts the AVG_ROW_LENGTH, MAX_ROWS and MIN_ROWS
+// options of the table spec. The values must be non-negative numbers.
+func convertRows(spec *sqlspec.Table, t *schema.Table) error {
+	for _, name := range []string{avgRowLength, maxRows, minRows} {
+		attr, ok := spec.Attr(name)
+		if !ok {
+			continue
+		}
+		n, err := attr.Int()
+		if err != nil {
+			return fmt.Errorf("table %q: invalid %s value: %w", spec.Name, name, err)
+		}
+		if n < 0 {
+			return fmt.Errorf("table %q: invalid %s value %d, expected a non-negative number", spec.Name, name, n)
+		}
+		t.AddAttrs(rowsAttr(name, uint64(n)))
+	}
+	return nil
+}
+
+// rowsAttr returns the schema attribute of the given rows option.
+func rowsAttr(name string, v uint64) schema.Attr {
+	switch name {
+	case avgRowLength:
+		return &AvgRowLength{V: v}
+	case maxRows:
+		return &MaxRows{V: v}
+	default:
+		return &MinRows{V: v}
+	}
+}
+
+// rowsOption returns the option name and value of the given rows attribute.
+func rowsOption(a schema.Attr) (name string, v uint64, ok bool) {
+	switch a := a.(type) {
+	case *AvgRowLength:
+		return avgRowLength, a.V, true
+	case *MaxRows:
+		return maxRows, a.V, true
+	case *MinRows:
+		return minRows, a.V, true
+	}
+	return "", 0, false
+}
+
+// serialIndexes adds the implicit UNIQUE indexes of SERIAL columns,
+// in case they are not covered by a primary key or a unique index.
+// Like MySQL, the index is named after the column, and suffixed with
+// "_2", "_3", etc. in case this name is already taken by another index.
+func serialIndexes(spec *sqlspec.Table, t *schema.Table) error {
+	for _, cs := range spec.Columns {
+		if cs.Type == nil || cs.Type.T != TypeSerial {
+			continue
+		}
+		c, ok := t.Column(cs.Name)
+		if !ok {
+			continue
+		}
+		// SERIAL implies AUTO_INCREMENT, and a table can have only one such column.
+		for _, o := range t.Columns {
+			if o != c && sqlx.Has(o.Attrs, &AutoIncrement{}) {
+				return fmt.Errorf("table %q: serial column %q cannot be used with another auto_increment column %q", t.Name, c.Name, o.Name)
+			}
+		}
+		if uniqueKey(t, c) {
+			continue
+		}
+		name := c.Name
+		for i := 2; ; i++ {
+			if _, ok := t.Index(name); !ok {
+				break
+			}
+			name = fmt.Sprintf("%s_%d", c.Name, i)
+		}
+		t.AddIndexes(schema.NewUniqueIndex(name).AddColumns(c))
+	}
+	return nil
+}
+
+// isSerial reports if the column matches the expansion of the SERIAL
+// pseudo-type: BIGINT UNSIGNED NOT NULL AUTO_INCREMENT UNIQUE.
+func isSerial(t *schema.Table, c *schema.Column) bool {
+	it, ok := c.Type.Type.(*schema.IntegerType)
+	if !ok || !strings.EqualFold(it.T, TypeBigInt) || !it.Unsigned || len(it.Attrs) > 0 || c.Type.Null || c.Default != nil {
+		return false
+	}
+	a := &AutoIncrement{}
+	return sqlx.Has(c.Attrs, a) && a.V == 0 && uniqueKey(t, c)
+}
+
+// uniqueKey reports if the column is a unique key of the table.
+func uniqueKey(t *schema.Table, c *schema.Column) bool {
+	for _, idx := range append([]*schema.Index{t.PrimaryKey}, t.Indexes...) {
+		if idx != nil && (idx == t.PrimaryKey || idx.Unique) && len(idx.Parts) == 1 && idx.Parts[0].C == c {
+			return true
+		}
 	}
-	if err := convertCharset(spec, &t.Attrs); err != nil {
-		return nil, err
+	return false
+}
+
+// convertPrimaryKey converts a sqlspec.PrimaryKey into a schema.Index.
+// Parts that are defined using "on" blocks may contain a prefix length.
+func convertPrimaryKey(spec *sqlspec.PrimaryKey, parent *schema.Table) (*schema.Index, error) {
+	pk, err := specutil.PrimaryKey(spec, parent)
+	if err != nil || pk == nil {
+		return pk, err
 	}
-	// MySQL allows setting the initial AUTO_INCREMENT value
-	// on the table definition.
-	if attr, ok := spec.Attr("auto_increment"); ok {
-		v, err := attr.Int64()
-		if err != nil {
+	for i, p := range spec.Parts {
+		if err := convertPart(&sqlspec.Index{Name: "PRIMARY"}, p, pk.Parts[i]); err != nil {
 			return nil, err
 		}
-		t.AddAttrs(&AutoIncrement{V: v})
 	}
-	return t, err
+	return pk, nil
 }
 
 // convertIndex converts a sqlspec.Index into a schema.Index.
 func convertIndex(spec *sqlspec.Index, parent *schema.Table) (*schema.Index, error) {
-	idx, err := specutil.Index(spec, parent, convertPart)
+	if err := checkIndexColumns(spec, parent); err != nil {
+		return nil, err
+	}
+	idx, err := specutil.Index(spec, parent, func(p *sqlspec.IndexPart, part *schema.IndexPart) error {
+		return convertPart(spec, p, part)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -130,20 +1127,82 @@ func convertIndex(spec *sqlspec.Index, parent *schema.Table) (*schema.Index, err
 		if err != nil {
 			return nil, err
 		}
+		if err := checkIndexType(idx, t); err != nil {
+			return nil, err
+		}
 		idx.AddAttrs(&IndexType{T: t})
 	}
+	if r, ok := spec.Remain().Resource("hint"); ok {
+		idx.AddAttrs(&IndexHint{R: r})
+	}
 	return idx, nil
 }
 
-func convertPart(spec *sqlspec.IndexPart, part *schema.IndexPart) error {
+// checkIndexColumns validates that all columns referenced by the index exist in
+// its table, and reports the missing column along with the index that uses it.
+func checkIndexColumns(spec *sqlspec.Index, t *schema.Table) error {
+	refs := append([]*schemahcl.Ref(nil), spec.Columns...)
+	for _, p := range spec.Parts {
+		if p.Column != nil {
+			refs = append(refs, p.Column)
+		}
+	}
+	for _, r := range refs {
+		if _, err := specutil.ColumnByRef(t, r); err != nil {
+			return fmt.Errorf("index %q of table %q: %w", spec.Name, t.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkIndexType validates that the columns of FULLTEXT and SPATIAL
+// indexes are supported by the index type, as MySQL rejects them otherwise.
+func checkIndexType(idx *schema.Index, t string) error {
+	switch t = strings.ToUpper(t); t {
+	case IndexTypeFullText, IndexTypeSpatial:
+	default:
+		return nil
+	}
+	if t == IndexTypeSpatial && len(idx.Parts) != 1 {
+		return fmt.Errorf("index %q: SPATIAL index must be defined on a single column", idx.Name)
+	}
+	for _, p := range idx.Parts {
+		if p.C == nil {
+			return fmt.Errorf("index %q: %s index does not support expression parts", idx.Name, t)
+		}
+		ct := p.C.Type
+		f, _ := FormatType(ct.Type)
+		switch t {
+		case IndexTypeFullText:
+			if _, ok := ct.Type.(*schema.StringType); !ok {
+				return fmt.Errorf("index %q: FULLTEXT index requires char, varchar or text columns, but column %q is of type %s", idx.Name, p.C.Name, f)
+			}
+		case IndexTypeSpatial:
+			if _, ok := ct.Type.(*schema.SpatialType); !ok {
+				return fmt.Errorf("index %q: SPATIAL index requires spatial columns, but column %q is of type %s", idx.Name, p.C.Name, f)
+			}
+			if ct.Null {
+				return fmt.Errorf("index %q: SPATIAL index requires NOT NULL columns, but column %q is nullable", idx.Name, p.C.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func convertPart(idx *sqlspec.Index, spec *sqlspec.IndexPart, part *schema.IndexPart) error {
 	if attr, ok := spec.Attr("prefix"); ok {
 		if part.X != nil {
-			return errors.New("attribute 'on.prefix' cannot be used in functional part")
+			return fmt.Errorf(`index %q: attribute "prefix" cannot be used in expression part %q at position %d`, idx.Name, spec.Expr, part.SeqNo)
 		}
 		p, err := attr.Int()
 		if err != nil {
 			return err
 		}
+		// MySQL requires a positive prefix length. Omit the
+		// attribute for indexing the full column value.
+		if p <= 0 {
+			return fmt.Errorf(`index %q: attribute "prefix" must be a positive number, got %d at position %d (omit it to index the full column)`, idx.Name, p, part.SeqNo)
+		}
 		part.AddAttrs(&SubPart{Len: p})
 	}
 	return nil
@@ -162,9 +1221,55 @@ func convertCheck(spec *sqlspec.Check) (*schema.Check, error) {
 		}
 		c.AddAttrs(&Enforced{V: b})
 	}
+	// Neither MySQL nor MariaDB support comments on CHECK constraints. Hence,
+	// they are rejected instead of being silently dropped on migration.
+	if _, ok := spec.Attr("comment"); ok {
+		return nil, fmt.Errorf("check %q: comments on CHECK constraints are not supported by MySQL or MariaDB", spec.Name)
+	}
 	return c, nil
 }
 
+// checkGeneratedRefs validates that the expressions of generated columns reference only
+// base columns, or generated columns that are defined before them in the table, and that
+// they do not reference auto-increment columns, as both are rejected by the database.
+func checkGeneratedRefs(t *schema.Table) error {
+	for i, c := range t.Columns {
+		x := &schema.GeneratedExpr{}
+		if !sqlx.Has(c.Attrs, x) {
+			continue
+		}
+		toks := checkTokens(x.Expr)
+		for j, tok := range toks {
+			// Skip function names.
+			if j+1 < len(toks) && toks[j+1] == "(" {
+				continue
+			}
+			// Qualified column names (e.g. t.c).
+			if k := strings.LastIndexByte(tok, '.'); k != -1 {
+				tok = tok[k+1:]
+			}
+			for k, r := range t.Columns {
+				switch {
+				case !strings.EqualFold(r.Name, tok):
+				case sqlx.Has(r.Attrs, &AutoIncrement{}):
+					return fmt.Errorf("generated column %q of table %q cannot reference auto-increment column %q", c.Name, t.Name, r.Name)
+				case k >= i && sqlx.Has(r.Attrs, &schema.GeneratedExpr{}):
+					return fmt.Errorf("generated column %q of table %q cannot reference generated column %q that is not defined before it", c.Name, t.Name, r.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hexDefault reports if x is a hexadecimal literal (e.g. 0x1F) used as a default
+// value of a binary type. Inspection reports binary defaults in this form, while
+// on other types (e.g. varchar), it is a string like any other.
+func hexDefault(t schema.Type, x string) bool {
+	_, ok := t.(*schema.BinaryType)
+	return ok && isHex(x)
+}
+
 // convertColumn converts a sqlspec.Column into a schema.Column.
 func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error) {
 	c, err := specutil.Column(spec, convertColumnType)
@@ -174,6 +1279,47 @@ func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error
 	if err := convertCharset(spec, &c.Attrs); err != nil {
 		return nil, err
 	}
+	// Quote string defaults of non-numeric types, as done by inspection, to
+	// distinguish them from numbers when marshaled back to HCL (e.g. "42").
+	if x, ok := c.Default.(*schema.Literal); ok && spec.Default.Type() == cty.String && !hasNumericDefault(c.Type.Type) && !hexDefault(c.Type.Type, x.V) {
+		x.V = quote(x.V)
+	}
+	// Boolean defaults can be written as 1, 0, true or false (in any case). They are
+	// normalized to true or false, the form used when marshaling the defaults of bool
+	// columns (including the 1 or 0 reported by inspection) back to HCL. Note, the diff
+	// compares boolean defaults by their value, and is not affected by this form.
+	if x, ok := c.Default.(*schema.Literal); ok && isBool(c.Type.Type) {
+		if b, err := boolValue(x.V); err == nil {
+			x.V = strconv.FormatBool(b)
+		}
+	}
+	// Hex string literals (e.g. X'00FF') and function calls (e.g. uuid_to_bin(uuid()))
+	// on binary columns are normalized to the form reported by inspection. Otherwise,
+	// they are written as quoted strings on migration, as any other raw expression.
+	if x, ok := c.Default.(*schema.RawExpr); ok {
+		if _, ok := c.Type.Type.(*schema.BinaryType); ok {
+			x.X = binDefault(x.X)
+		}
+	}
+	// An explicit "default = null" is preserved as DEFAULT NULL.
+	if d := spec.Default; d != cty.NilVal && d.IsNull() {
+		if !spec.Null {
+			return nil, fmt.Errorf("column %q: NOT NULL column cannot have a NULL default value", spec.Name)
+		}
+		c.Default = &schema.RawExpr{X: "NULL"}
+	}
+	// SERIAL implies NOT NULL AUTO_INCREMENT. The UNIQUE
+	// index is added on the table level by convertTable.
+	if spec.Type.T == TypeSerial {
+		if spec.Null {
+			return nil, fmt.Errorf("column %q: type serial cannot be nullable", spec.Name)
+		}
+		c.AddAttrs(&AutoIncrement{})
+	}
+	// The national types imply the utf8 (utf8mb3) charset.
+	if t := spec.Type.T; (t == TypeNChar || t == TypeNVarchar) && !sqlx.Has(c.Attrs, &schema.Charset{}) {
+		c.AddAttrs(&schema.Charset{V: "utf8mb3"})
+	}
 	if attr, ok := spec.Attr("on_update"); ok {
 		x, err := attr.RawExpr()
 		if err != nil {
@@ -190,19 +1336,200 @@ func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error
 			c.AddAttrs(&AutoIncrement{})
 		}
 	}
+	if attr, ok := spec.Attr("auto_random"); ok {
+		if sqlx.Has(c.Attrs, &AutoIncrement{}) {
+			return nil, fmt.Errorf("column %q: attributes \"auto_increment\" and \"auto_random\" are mutually exclusive", spec.Name)
+		}
+		a, err := autoRandom(attr)
+		if err != nil {
+			return nil, err
+		}
+		if a != nil {
+			c.AddAttrs(a)
+		}
+	}
+	if err := checkTimePrecision(c); err != nil {
+		return nil, err
+	}
+	if attr, ok := spec.Attr("srid"); ok {
+		if _, ok := c.Type.Type.(*schema.SpatialType); !ok {
+			return nil, fmt.Errorf("column %q: attribute \"srid\" is supported only for spatial types", spec.Name)
+		}
+		id, err := attr.Int()
+		if err != nil {
+			return nil, err
+		}
+		c.AddAttrs(&SRID{ID: id})
+	}
+	if attr, ok := spec.Attr("compressed"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		if !compressible(c.Type.Type) {
+			return nil, fmt.Errorf("column %q: attribute \"compressed\" is supported only for string, binary and json types", spec.Name)
+		}
+		if b {
+			c.AddAttrs(&Compressed{})
+		}
+	}
+	if err := convertPosition(spec, &c.Attrs); err != nil {
+		return nil, err
+	}
 	if err := specutil.ConvertGenExpr(spec.Remain(), c, storedOrVirtual); err != nil {
 		return nil, err
 	}
 	return c, err
 }
 
+// Binary default expressions that are normalized by binDefault:
+// hexadecimal string literals (e.g. x'0A') and function calls.
+var (
+	reHexString = regexp.MustCompile(`^[xX]'([0-9a-fA-F]*)'$`)
+	reFuncCall  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*\s*\(.*\)$`)
+)
+
+// binDefault returns the normalized form of the given binary default expression.
+func binDefault(x string) string {
+	switch {
+	case reHexString.MatchString(x):
+		return "0x" + reHexString.FindStringSubmatch(x)[1]
+	case reFuncCall.MatchString(x):
+		return "(" + x + ")"
+	default:
+		return x
+	}
+}
+
+// reCurrTimestampP matches the CURRENT_TIMESTAMP (or NOW) expressions and their precision.
+var reCurrTimestampP = regexp.MustCompile(`(?i)^(?:current_timestamp|now)(?:\((\d?)\))?$`)
+
+// checkTimePrecision checks that the fractional seconds precision of the CURRENT_TIMESTAMP
+// expressions in the DEFAULT and ON UPDATE clauses matches the precision of the column type,
+// as MySQL rejects such definitions.
+func checkTimePrecision(c *schema.Column) error {
+	t, ok := c.Type.Type.(*schema.TimeType)
+	if !ok {
+		return nil
+	}
+	var p int
+	if t.Precision != nil {
+		p = *t.Precision
+	}
+	check := func(clause, x string) error {
+		m := reCurrTimestampP.FindStringSubmatch(strings.TrimSpace(x))
+		if m == nil {
+			return nil
+		}
+		var xp int
+		if m[1] != "" {
+			xp, _ = strconv.Atoi(m[1])
+		}
+		if xp != p {
+			return fmt.Errorf("column %q: %s precision %d (%s) does not match type precision %d (%s)", c.Name, clause, xp, x, p, t.T)
+		}
+		return nil
+	}
+	if x, ok := c.Default.(*schema.RawExpr); ok {
+		if err := check("default", x.X); err != nil {
+			return err
+		}
+	}
+	if u := (OnUpdate{}); sqlx.Has(c.Attrs, &u) {
+		if err := check("on_update", u.A); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// autoRandom converts the "auto_random" attribute of a column spec to an AutoRandom
+// attribute. The attribute value is either a bool or the number of shard bits.
+func autoRandom(attr *schemahcl.Attr) (*AutoRandom, error) {
+	if attr.V.Type() == cty.Bool {
+		b, err := attr.Bool()
+		if err != nil || !b {
+			return nil, err
+		}
+		return &AutoRandom{}, nil
+	}
+	n, err := attr.Int()
+	if err != nil {
+		return nil, fmt.Errorf(`unexpected type %s for attribute "auto_random"`, attr.V.Type().FriendlyName())
+	}
+	if n < 1 || n > 15 {
+		return nil, fmt.Errorf(`invalid number of shard bits for attribute "auto_random": %d`, n)
+	}
+	return &AutoRandom{ShardBits: n}, nil
+}
+
+// convertPosition converts the "first" and "after" attributes of a column
+// spec to a ColumnPosition attribute.
+func convertPosition(spec *sqlspec.Column, attrs *[]schema.Attr) error {
+	var p ColumnPosition
+	if attr, ok := spec.Attr("first"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return err
+		}
+		p.First = b
+	}
+	if attr, ok := spec.Attr("after"); ok {
+		s, err := attr.String()
+		if err != nil {
+			return err
+		}
+		p.After = s
+	}
+	switch {
+	case p.First && p.After != "":
+		return fmt.Errorf("column %q: attributes \"first\" and \"after\" are mutually exclusive", spec.Name)
+	case p.First || p.After != "":
+		*attrs = append(*attrs, &p)
+	}
+	return nil
+}
+
 // convertColumnType converts a sqlspec.Column into a concrete MySQL schema.Type.
 func convertColumnType(spec *sqlspec.Column) (schema.Type, error) {
-	return TypeRegistry.Type(spec.Type, spec.Extra.Attrs)
+	t, err := TypeRegistry.Type(spec.Type, spec.Extra.Attrs)
+	if err != nil {
+		return nil, err
+	}
+	// Preserve the display width of integer types, in case it was defined
+	// explicitly in the document (e.g. int(11)). Note, the display width is
+	// ignored by the diff, as it is deprecated and ignored by MySQL 8.
+	if it, ok := t.(*schema.IntegerType); ok && !sqlx.Has(it.Attrs, &DisplayWidth{}) {
+		if a, ok := findTypeAttr(spec.Type, "size"); ok {
+			n, err := a.Int()
+			if err != nil {
+				return nil, err
+			}
+			if n > 0 {
+				it.Attrs = append([]schema.Attr{&DisplayWidth{N: n}}, it.Attrs...)
+			}
+		}
+	}
+	return t, nil
+}
+
+// findTypeAttr returns the type attribute with the given name.
+func findTypeAttr(t *schemahcl.Type, name string) (*schemahcl.Attr, bool) {
+	for _, a := range t.Attrs {
+		if a.K == name {
+			return a, true
+		}
+	}
+	return nil, false
 }
 
 // schemaSpec converts from a concrete MySQL schema to Atlas specification.
 func schemaSpec(s *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error) {
+	return schemaSpecWith(s, tableSpec)
+}
+
+// schemaSpecWith is like schemaSpec, but converts the tables using the given function.
+func schemaSpecWith(s *schema.Schema, tableSpec specutil.TableSpecFunc) (*sqlspec.Schema, []*sqlspec.Table, error) {
 	sc, t, err := specutil.FromSchema(s, tableSpec)
 	if err != nil {
 		return nil, nil, err
@@ -218,10 +1545,17 @@ func schemaSpec(s *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error) {
 
 // tableSpec converts from a concrete MySQL sqlspec.Table to a schema.Table.
 func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
+	return tableSpecWith(t, TypeRegistry)
+}
+
+// tableSpecWith is like tableSpec, but converts the column types using the given registry.
+func tableSpecWith(t *schema.Table, r *schemahcl.TypeRegistry) (*sqlspec.Table, error) {
 	ts, err := specutil.FromTable(
 		t,
-		columnSpec,
-		specutil.FromPrimaryKey,
+		func(c *schema.Column, t *schema.Table) (*sqlspec.Column, error) {
+			return columnSpec(c, t, r)
+		},
+		primaryKeySpec,
 		indexSpec,
 		specutil.FromForeignKey,
 		checkSpec,
@@ -235,6 +1569,75 @@ func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 	if c, ok := hasCollate(t.Attrs, t.Schema.Attrs); ok {
 		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("collate", c))
 	}
+	if e := (Encryption{}); sqlx.Has(t.Attrs, &e) {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("encryption", yesNo(e.V)))
+	}
+	if c := (Compression{}); sqlx.Has(t.Attrs, &c) {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("compression", c.V))
+	}
+	if h := (MigrationHints{}); sqlx.Has(t.Attrs, &h) {
+		r := &schemahcl.Resource{Type: "migration"}
+		if h.Algorithm != "" {
+			r.Attrs = append(r.Attrs, specutil.VarAttr("algorithm", h.Algorithm))
+		}
+		if h.Lock != "" {
+			r.Attrs = append(r.Attrs, specutil.VarAttr("lock", h.Lock))
+		}
+		ts.Extra.Children = append(ts.Extra.Children, r)
+	}
+	if u := (Union{}); sqlx.Has(t.Attrs, &u) {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringsAttr("union", u.T...))
+	}
+	if m := (InsertMethod{}); sqlx.Has(t.Attrs, &m) {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("insert_method", m.V))
+	}
+	if sqlx.Has(t.Attrs, &Temporary{}) {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.BoolAttr("temporary", true))
+	}
+	for _, a := range t.Attrs {
+		if name, v, ok := statsOption(a); ok {
+			if n, err := strconv.Atoi(v); err == nil {
+				ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.IntAttr(name, n))
+			} else {
+				ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr(name, v))
+			}
+		}
+	}
+	for _, a := range t.Attrs {
+		if name, v, ok := rowsOption(a); ok {
+			ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.IntAttr(name, int(v)))
+		}
+	}
+	for _, a := range t.Attrs {
+		if u, ok := a.(*UnknownAttr); ok {
+			ts.Extra.Attrs = append(ts.Extra.Attrs, u.A)
+		}
+	}
+	sortAttrs(ts.Extra.Attrs, tableAttrsOrder)
+	return ts, nil
+}
+
+// serialTableSpec is like tableSpec, but collapses the columns
+// that match the expansion of SERIAL back into "serial".
+func serialTableSpec(t *schema.Table) (*sqlspec.Table, error) {
+	ts, err := tableSpec(t)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range t.Columns {
+		if !isSerial(t, c) {
+			continue
+		}
+		spec := ts.Columns[i]
+		spec.Type = &schemahcl.Type{T: TypeSerial}
+		attrs := spec.Extra.Attrs[:0]
+		for _, a := range spec.Extra.Attrs {
+			if a.K != "unsigned" && a.K != "auto_increment" {
+				attrs = append(attrs, a)
+			}
+		}
+		spec.Extra.Attrs = attrs
+	}
 	return ts, nil
 }
 
@@ -247,6 +1650,32 @@ func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 	if i := (IndexType{}); sqlx.Has(idx.Attrs, &i) && i.T != IndexTypeBTree {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("type", strings.ToUpper(i.T)))
 	}
+	if h := (IndexHint{}); sqlx.Has(idx.Attrs, &h) && h.R != nil {
+		spec.Extra.Children = append(spec.Extra.Children, h.R)
+	}
+	return spec, nil
+}
+
+// primaryKeySpec converts a schema.Index into a sqlspec.PrimaryKey. Parts with a prefix
+// length are defined using "on" blocks, as the "columns" attribute cannot hold them.
+func primaryKeySpec(pk *schema.Index) (*sqlspec.PrimaryKey, error) {
+	prefixed := false
+	for _, p := range pk.Parts {
+		prefixed = prefixed || sqlx.Has(p.Attrs, &SubPart{})
+	}
+	if !prefixed {
+		return specutil.FromPrimaryKey(pk)
+	}
+	spec := &sqlspec.PrimaryKey{Parts: make([]*sqlspec.IndexPart, len(pk.Parts))}
+	for i, p := range pk.Parts {
+		if p.C == nil {
+			return nil, fmt.Errorf("missing column for key part %d of the primary key", i)
+		}
+		spec.Parts[i] = &sqlspec.IndexPart{Column: specutil.ColumnRef(p.C.Name), Desc: p.Desc}
+		if err := partAttr(pk, p, spec.Parts[i]); err != nil {
+			return nil, err
+		}
+	}
 	return spec, nil
 }
 
@@ -258,11 +1687,23 @@ func partAttr(_ *schema.Index, part *schema.IndexPart, spec *sqlspec.IndexPart)
 }
 
 // columnSpec converts from a concrete MySQL schema.Column into a sqlspec.Column.
-func columnSpec(c *schema.Column, t *schema.Table) (*sqlspec.Column, error) {
-	spec, err := specutil.FromColumn(c, columnTypeSpec)
+func columnSpec(c *schema.Column, t *schema.Table, r *schemahcl.TypeRegistry) (*sqlspec.Column, error) {
+	spec, err := specutil.FromColumn(c, func(t schema.Type) (*sqlspec.Column, error) {
+		return columnTypeSpec(t, r)
+	})
 	if err != nil {
 		return nil, err
 	}
+	if nullDefault(c) {
+		spec.Default = cty.NilVal
+		spec.Extra.Attrs = append(spec.Extra.Attrs, &schemahcl.Attr{K: "default", V: cty.NullVal(cty.DynamicPseudoType)})
+	}
+	// Inspected boolean defaults (1 or 0) are printed as true or false.
+	if x, ok := c.Default.(*schema.Literal); ok && isBool(c.Type.Type) {
+		if b, err := boolValue(x.V); err == nil {
+			spec.Default = cty.BoolVal(b)
+		}
+	}
 	if c, ok := hasCharset(c.Attrs, t.Attrs); ok {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("charset", c))
 	}
@@ -275,12 +1716,71 @@ func columnSpec(c *schema.Column, t *schema.Table) (*sqlspec.Column, error) {
 	if sqlx.Has(c.Attrs, &AutoIncrement{}) {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("auto_increment", true))
 	}
+	if a := (AutoRandom{}); sqlx.Has(c.Attrs, &a) {
+		if a.ShardBits > 0 {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.IntAttr("auto_random", a.ShardBits))
+		} else {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("auto_random", true))
+		}
+	}
+	if s := (SRID{}); sqlx.Has(c.Attrs, &s) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.IntAttr("srid", s.ID))
+	}
+	if sqlx.Has(c.Attrs, &Compressed{}) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("compressed", true))
+	}
+	if p := (ColumnPosition{}); sqlx.Has(c.Attrs, &p) {
+		if p.First {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("first", true))
+		}
+		if p.After != "" {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("after", p.After))
+		}
+	}
 	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
 		spec.Extra.Children = append(spec.Extra.Children, specutil.FromGenExpr(x, storedOrVirtual))
 	}
+	for _, a := range c.Attrs {
+		if u, ok := a.(*UnknownAttr); ok {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, u.A)
+		}
+	}
+	sortAttrs(spec.Extra.Attrs, columnAttrsOrder)
 	return spec, nil
 }
 
+// The canonical order of the table and column attributes in marshaled documents.
+var (
+	tableAttrsOrder = []string{
+		"comment", "charset", "collate", "encryption", "compression", "union", "insert_method", "temporary",
+		statsPersistent, statsAutoRecalc, statsSamplePages, avgRowLength, maxRows, minRows,
+	}
+	columnAttrsOrder = []string{
+		"unsigned", "zerofill", "comment", "default", "charset", "collate", "on_update", "auto_increment", "auto_random", "srid", "compressed", "first", "after",
+	}
+)
+
+// sortAttrs sorts the attributes by the given canonical order, to keep the marshaled
+// documents deterministic regardless of the order the schema attributes were added.
+// Attributes that are missing from the order are placed last, sorted by their names.
+func sortAttrs(attrs []*schemahcl.Attr, order []string) {
+	rank := func(k string) int {
+		for i, o := range order {
+			if o == k {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		ri, rj := rank(attrs[i].K), rank(attrs[j].K)
+		if ri != rj {
+			return ri < rj
+		}
+		return ri == len(order) && attrs[i].K < attrs[j].K
+	})
+}
+
 // storedOrVirtual returns a STORED or VIRTUAL
 // generated type option based on the given string.
 func storedOrVirtual(s string) string {
@@ -299,23 +1799,29 @@ func storedOrVirtual(s string) string {
 func checkSpec(s *schema.Check) *sqlspec.Check {
 	c := specutil.FromCheck(s)
 	if e := (Enforced{}); sqlx.Has(s.Attrs, &e) {
-		c.Extra.Attrs = append(c.Extra.Attrs, schemahcl.BoolAttr("enforced", true))
+		c.Extra.Attrs = append(c.Extra.Attrs, schemahcl.BoolAttr("enforced", e.V))
 	}
 	return c
 }
 
 // columnTypeSpec converts from a concrete MySQL schema.Type into sqlspec.Column Type.
-func columnTypeSpec(t schema.Type) (*sqlspec.Column, error) {
-	st, err := TypeRegistry.Convert(t)
+func columnTypeSpec(t schema.Type, r *schemahcl.TypeRegistry) (*sqlspec.Column, error) {
+	st, err := r.Convert(t)
 	if err != nil {
 		return nil, err
 	}
 	c := &sqlspec.Column{Type: st}
-	for _, attr := range st.Attrs {
-		// TODO(rotemtam): infer this from the TypeSpec
-		if attr.K == "unsigned" {
-			c.Extra.Attrs = append(c.Extra.Attrs, attr)
+	for _, ts := range r.Specs() {
+		if ts.T != st.T {
+			continue
+		}
+		// Type modifiers are also defined as column attributes.
+		for _, attr := range st.Attrs {
+			if ta, ok := ts.Attr(attr.K); ok && ta.Modifier {
+				c.Extra.Attrs = append(c.Extra.Attrs, attr)
+			}
 		}
+		break
 	}
 	return c, nil
 }
@@ -347,112 +1853,239 @@ func convertCharset(spec specutil.Attrer, attrs *[]schema.Attr) error {
 
 // hasCharset reports if the attribute contains the "charset" attribute,
 // and it needs to be defined explicitly on the schema. This is true, in
-// case the element charset is different from its parent charset.
+// case the element charset is different from its parent charset, or in
+// case the element defines only a charset, and its implied (default)
+// collation is different from the parent collation.
 func hasCharset(attr []schema.Attr, parent []schema.Attr) (string, bool) {
-	var c, p schema.Charset
-	if sqlx.Has(attr, &c) && (parent == nil || sqlx.Has(parent, &p) && c.V != p.V) {
+	var c schema.Charset
+	if !sqlx.Has(attr, &c) {
+		return "", false
+	}
+	if parent == nil {
+		return c.V, true
+	}
+	pch, pco, ok := impliedCharset(parent)
+	if !ok {
+		return "", false
+	}
+	if c.V != pch {
+		return c.V, true
+	}
+	// Omitting the charset of a charset-only element makes it
+	// inherit the parent collation instead of its default one.
+	if _, co, _ := impliedCharset(attr); !sqlx.Has(attr, &schema.Collation{}) && co != "" && co != pco {
 		return c.V, true
 	}
 	return "", false
 }
 
 // hasCollate reports if the attribute contains the "collation"/"collate" attribute,
-// and it needs to be defined explicitly on the schema. This is true, in
-// case the element collation is different from its parent collation.
+// and it needs to be defined explicitly on the schema. This is true, in case the
+// element collation is different from its parent collation, either explicit or
+// implied by the parent charset.
 func hasCollate(attr []schema.Attr, parent []schema.Attr) (string, bool) {
-	var c, p schema.Collation
-	if sqlx.Has(attr, &c) && (parent == nil || sqlx.Has(parent, &p) && c.V != p.V) {
+	var c schema.Collation
+	if !sqlx.Has(attr, &c) {
+		return "", false
+	}
+	if parent == nil {
+		return c.V, true
+	}
+	if _, pco, ok := impliedCharset(parent); ok && c.V != pco {
 		return c.V, true
 	}
 	return "", false
 }
 
+// impliedCharset returns the charset and collation defined by the attributes.
+// In case only one of them is defined, the other is derived from it using the
+// MySQL 8 defaults. False is returned if none of them is defined.
+func impliedCharset(attrs []schema.Attr) (charset, collation string, ok bool) {
+	var (
+		ch schema.Charset
+		co schema.Collation
+	)
+	switch hasCh, hasCo := sqlx.Has(attrs, &ch), sqlx.Has(attrs, &co); {
+	case hasCh && hasCo:
+		return ch.V, co.V, true
+	case hasCh:
+		m, _ := mysqlversion.V("8.0.0").CharsetToCollate()
+		return ch.V, m[ch.V], true
+	case hasCo:
+		m, _ := mysqlversion.V("8.0.0").CollateToCharset()
+		return m[co.V], co.V, true
+	}
+	return "", "", false
+}
+
+// The maximum number of members that MySQL allows defining in ENUM and SET types.
+const (
+	maxEnumValues = 65535
+	maxSetValues  = 64
+)
+
 // TypeRegistry contains the supported TypeSpecs for the mysql driver.
-var TypeRegistry = schemahcl.NewRegistry(
-	schemahcl.WithFormatter(FormatType),
-	schemahcl.WithParser(ParseType),
-	schemahcl.WithSpecs(
-		&schemahcl.TypeSpec{
-			Name: TypeEnum,
-			T:    TypeEnum,
-			Attributes: []*schemahcl.TypeAttr{
-				{Name: "values", Kind: reflect.Slice, Required: true},
-			},
-			RType: reflect.TypeOf(schema.EnumType{}),
-			FromSpec: func(t *schemahcl.Type) (schema.Type, error) {
-				if len(t.Attrs) != 1 || t.Attrs[0].K != "values" {
-					return nil, fmt.Errorf("invalid enum type spec: %v", t)
-				}
-				v, err := t.Attrs[0].Strings()
-				if err != nil {
-					return nil, err
-				}
-				return &schema.EnumType{T: "enum", Values: v}, nil
-			},
-		},
-		&schemahcl.TypeSpec{
-			Name: TypeSet,
-			T:    TypeSet,
-			Attributes: []*schemahcl.TypeAttr{
-				{Name: "values", Kind: reflect.Slice, Required: true},
+var TypeRegistry = NewTypeRegistry()
+
+// NewTypeRegistry returns a registry of the supported TypeSpecs for the mysql driver,
+// that formats types using the given options. For example, the types converted by
+// NewTypeRegistry(OmitDefaults()) omit their default sizes (e.g. "decimal").
+func NewTypeRegistry(opts ...FormatOption) *schemahcl.TypeRegistry {
+	cfg := newFormatConfig(opts)
+	return schemahcl.NewRegistry(
+		schemahcl.WithFormatter(func(t schema.Type) (string, error) {
+			return FormatType(t, opts...)
+		}),
+		schemahcl.WithParser(parseType),
+		schemahcl.WithSpecs(
+			&schemahcl.TypeSpec{
+				Name: TypeEnum,
+				T:    TypeEnum,
+				Attributes: []*schemahcl.TypeAttr{
+					{Name: "values", Kind: reflect.Slice, Required: true},
+				},
+				RType: reflect.TypeOf(schema.EnumType{}),
+				FromSpec: func(t *schemahcl.Type) (schema.Type, error) {
+					if len(t.Attrs) != 1 || t.Attrs[0].K != "values" {
+						return nil, fmt.Errorf("invalid enum type spec: %v", t)
+					}
+					v, err := t.Attrs[0].Strings()
+					if err != nil {
+						return nil, err
+					}
+					if len(v) > maxEnumValues {
+						return nil, fmt.Errorf("enum type has %d values, exceeding the maximum of %d", len(v), maxEnumValues)
+					}
+					return &schema.EnumType{T: "enum", Values: v}, nil
+				},
 			},
-			RType: reflect.TypeOf(SetType{}),
-			FromSpec: func(t *schemahcl.Type) (schema.Type, error) {
-				if len(t.Attrs) != 1 || t.Attrs[0].K != "values" {
-					return nil, fmt.Errorf("invalid set type spec: %v", t)
-				}
-				v, err := t.Attrs[0].Strings()
-				if err != nil {
-					return nil, err
-				}
-				return &SetType{Values: v}, nil
+			&schemahcl.TypeSpec{
+				Name: TypeSet,
+				T:    TypeSet,
+				Attributes: []*schemahcl.TypeAttr{
+					{Name: "values", Kind: reflect.Slice, Required: true},
+				},
+				RType: reflect.TypeOf(SetType{}),
+				FromSpec: func(t *schemahcl.Type) (schema.Type, error) {
+					if len(t.Attrs) != 1 || t.Attrs[0].K != "values" {
+						return nil, fmt.Errorf("invalid set type spec: %v", t)
+					}
+					v, err := t.Attrs[0].Strings()
+					if err != nil {
+						return nil, err
+					}
+					if len(v) > maxSetValues {
+						return nil, fmt.Errorf("set type has %d values, exceeding the maximum of %d", len(v), maxSetValues)
+					}
+					return &SetType{Values: v}, nil
+				},
 			},
-		},
-		schemahcl.NewTypeSpec(TypeBool),
-		schemahcl.NewTypeSpec(TypeBoolean),
-		schemahcl.NewTypeSpec(TypeBit, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeInt, schemahcl.WithAttributes(unsignedTypeAttr(), schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeTinyInt, schemahcl.WithAttributes(unsignedTypeAttr(), schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeSmallInt, schemahcl.WithAttributes(unsignedTypeAttr(), schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeMediumInt, schemahcl.WithAttributes(unsignedTypeAttr(), schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeBigInt, schemahcl.WithAttributes(unsignedTypeAttr(), schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeDecimal, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
-		schemahcl.NewTypeSpec(TypeNumeric, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
-		schemahcl.NewTypeSpec(TypeFloat, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
-		schemahcl.NewTypeSpec(TypeDouble, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
-		schemahcl.NewTypeSpec(TypeReal, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
-		schemahcl.NewTypeSpec(TypeTimestamp, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
-		schemahcl.NewTypeSpec(TypeDate),
-		schemahcl.NewTypeSpec(TypeTime, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
-		schemahcl.NewTypeSpec(TypeDateTime, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
-		schemahcl.NewTypeSpec(TypeYear, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
-		schemahcl.NewTypeSpec(TypeVarchar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(true))),
-		schemahcl.NewTypeSpec(TypeChar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeVarBinary, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(true))),
-		schemahcl.NewTypeSpec(TypeBinary, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeBlob, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeTinyBlob),
-		schemahcl.NewTypeSpec(TypeMediumBlob),
-		schemahcl.NewTypeSpec(TypeLongBlob),
-		schemahcl.NewTypeSpec(TypeJSON),
-		schemahcl.NewTypeSpec(TypeText, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
-		schemahcl.NewTypeSpec(TypeTinyText),
-		schemahcl.NewTypeSpec(TypeMediumText),
-		schemahcl.NewTypeSpec(TypeLongText),
-		schemahcl.NewTypeSpec(TypeGeometry),
-		schemahcl.NewTypeSpec(TypePoint),
-		schemahcl.NewTypeSpec(TypeMultiPoint),
-		schemahcl.NewTypeSpec(TypeLineString),
-		schemahcl.NewTypeSpec(TypeMultiLineString),
-		schemahcl.NewTypeSpec(TypePolygon),
-		schemahcl.NewTypeSpec(TypeMultiPolygon),
-		schemahcl.NewTypeSpec(TypeGeometryCollection),
-	),
-)
+			schemahcl.NewTypeSpec(TypeBool),
+			schemahcl.NewTypeSpec(TypeBoolean),
+			schemahcl.NewTypeSpec(TypeSerial),
+			schemahcl.NewTypeSpec(TypeBit, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+			schemahcl.NewTypeSpec(TypeInt, schemahcl.WithAttributes(unsignedTypeAttr(), zerofillTypeAttr(), schemahcl.SizeTypeAttr(false)), schemahcl.WithToSpec(integerTypeSpec)),
+			schemahcl.NewTypeSpec(TypeTinyInt, schemahcl.WithAttributes(unsignedTypeAttr(), zerofillTypeAttr(), schemahcl.SizeTypeAttr(false)), schemahcl.WithToSpec(integerTypeSpec)),
+			schemahcl.NewTypeSpec(TypeSmallInt, schemahcl.WithAttributes(unsignedTypeAttr(), zerofillTypeAttr(), schemahcl.SizeTypeAttr(false)), schemahcl.WithToSpec(integerTypeSpec)),
+			schemahcl.NewTypeSpec(TypeMediumInt, schemahcl.WithAttributes(unsignedTypeAttr(), zerofillTypeAttr(), schemahcl.SizeTypeAttr(false)), schemahcl.WithToSpec(integerTypeSpec)),
+			schemahcl.NewTypeSpec(TypeBigInt, schemahcl.WithAttributes(unsignedTypeAttr(), zerofillTypeAttr(), schemahcl.SizeTypeAttr(false)), schemahcl.WithToSpec(integerTypeSpec)),
+			schemahcl.NewTypeSpec(TypeDecimal, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false}), schemahcl.WithToSpec(cfg.decimalTypeSpec(TypeDecimal))),
+			schemahcl.NewTypeSpec(TypeNumeric, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false}), schemahcl.WithToSpec(cfg.decimalTypeSpec(TypeNumeric))),
+			schemahcl.NewTypeSpec(TypeFloat, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
+			schemahcl.NewTypeSpec(TypeDouble, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
+			schemahcl.NewTypeSpec(TypeReal, schemahcl.WithAttributes(unsignedTypeAttr(), &schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
+			schemahcl.NewTypeSpec(TypeTimestamp, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
+			schemahcl.NewTypeSpec(TypeDate),
+			schemahcl.NewTypeSpec(TypeTime, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
+			schemahcl.NewTypeSpec(TypeDateTime, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
+			schemahcl.NewTypeSpec(TypeYear, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
+			schemahcl.NewTypeSpec(TypeVarchar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(true))),
+			schemahcl.NewTypeSpec(TypeChar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+			schemahcl.NewTypeSpec(TypeNVarchar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(true))),
+			schemahcl.NewTypeSpec(TypeNChar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+			schemahcl.NewTypeSpec(TypeVarBinary, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(true))),
+			schemahcl.NewTypeSpec(TypeBinary, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+			schemahcl.NewTypeSpec(TypeBlob, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+			schemahcl.NewTypeSpec(TypeTinyBlob),
+			schemahcl.NewTypeSpec(TypeMediumBlob),
+			schemahcl.NewTypeSpec(TypeLongBlob),
+			schemahcl.NewTypeSpec(TypeJSON),
+			schemahcl.NewTypeSpec(TypeText, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+			schemahcl.NewTypeSpec(TypeTinyText),
+			schemahcl.NewTypeSpec(TypeMediumText),
+			schemahcl.NewTypeSpec(TypeLongText),
+			schemahcl.NewTypeSpec(TypeGeometry),
+			schemahcl.NewTypeSpec(TypePoint),
+			schemahcl.NewTypeSpec(TypeMultiPoint),
+			schemahcl.NewTypeSpec(TypeLineString),
+			schemahcl.NewTypeSpec(TypeMultiLineString),
+			schemahcl.NewTypeSpec(TypePolygon),
+			schemahcl.NewTypeSpec(TypeMultiPolygon),
+			schemahcl.NewTypeSpec(TypeGeometryCollection),
+		),
+	)
+}
 
 func unsignedTypeAttr() *schemahcl.TypeAttr {
 	return &schemahcl.TypeAttr{
-		Name: "unsigned",
-		Kind: reflect.Bool,
+		Name:     "unsigned",
+		Kind:     reflect.Bool,
+		Modifier: true,
+	}
+}
+
+// zerofillTypeAttr returns the ZEROFILL attribute of integer types.
+// Note that in MySQL, ZEROFILL implies the UNSIGNED attribute.
+func zerofillTypeAttr() *schemahcl.TypeAttr {
+	return &schemahcl.TypeAttr{
+		Name:     "zerofill",
+		Kind:     reflect.Bool,
+		Modifier: true,
+	}
+}
+
+// decimalTypeSpec returns the function that converts the decimal types with the given
+// name into their spec representation, in case their default precision and scale are
+// omitted. Otherwise, nil is returned and the registry converts them by their attributes.
+func (c *formatConfig) decimalTypeSpec(name string) func(schema.Type) (*schemahcl.Type, error) {
+	if !c.omitDefaults {
+		return nil
+	}
+	return func(t schema.Type) (*schemahcl.Type, error) {
+		dt, ok := t.(*schema.DecimalType)
+		if !ok {
+			return nil, fmt.Errorf("mysql: unexpected decimal type: %T", t)
+		}
+		s := &schemahcl.Type{T: name}
+		// Similar to the registry conversion, trailing zero-value
+		// attributes are skipped, and the rest are kept in order.
+		switch {
+		case !defaultDecimal(dt) && dt.Scale > 0:
+			s.Attrs = []*schemahcl.Attr{schemahcl.BoolAttr("unsigned", dt.Unsigned), schemahcl.IntAttr("precision", dt.Precision), schemahcl.IntAttr("scale", dt.Scale)}
+		case !defaultDecimal(dt):
+			s.Attrs = []*schemahcl.Attr{schemahcl.BoolAttr("unsigned", dt.Unsigned), schemahcl.IntAttr("precision", dt.Precision)}
+		case dt.Unsigned:
+			s.Attrs = []*schemahcl.Attr{schemahcl.BoolAttr("unsigned", true)}
+		}
+		return s, nil
+	}
+}
+
+// integerTypeSpec converts an integer type into its spec representation,
+// including its ZEROFILL and display width attributes (if exist).
+func integerTypeSpec(t schema.Type) (*schemahcl.Type, error) {
+	it, ok := t.(*schema.IntegerType)
+	if !ok {
+		return nil, fmt.Errorf("mysql: unexpected integer type: %T", t)
+	}
+	s := &schemahcl.Type{T: it.T}
+	if w := (DisplayWidth{}); sqlx.Has(it.Attrs, &w) && w.N > 0 {
+		s.Attrs = append(s.Attrs, schemahcl.IntAttr("size", w.N))
+	}
+	if z := (ZeroFill{}); sqlx.Has(it.Attrs, &z) {
+		s.Attrs = append(s.Attrs, schemahcl.BoolAttr("unsigned", true), schemahcl.BoolAttr("zerofill", true))
+	} else if it.Unsigned {
+		s.Attrs = append(s.Attrs, schemahcl.BoolAttr("unsigned", true))
 	}
+	return s, nil
 }
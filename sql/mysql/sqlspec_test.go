@@ -5,11 +5,24 @@
 package mysql
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
+	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/internal/spectest"
+	"ariga.io/atlas/sql/internal/specutil"
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlspec"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/stretchr/testify/require"
 )
 
@@ -37,6 +50,7 @@ table "table" {
 	}
 	column "account_name" {
 		type = varchar(32)
+		null = true
 	}
 	column "created_at" {
 		type    = datetime(4)
@@ -155,6 +169,7 @@ table "accounts" {
 							T:    TypeVarchar,
 							Size: 32,
 						},
+						Null: true,
 					},
 				},
 				{
@@ -394,6 +409,85 @@ schema "test" {
 	require.Equal(t, utf8mb4, b.Attrs)
 }
 
+func TestMarshalSpec_ImpliedCollation(t *testing.T) {
+	text := &schema.StringType{T: "text"}
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("users").
+				SetCharset("utf8mb4").
+				AddColumns(
+					schema.NewColumn("a").SetType(text).SetCharset("utf8mb4").SetCollation("utf8mb4_0900_ai_ci"),
+					schema.NewColumn("b").SetType(text).SetCharset("utf8mb4"),
+					schema.NewColumn("c").SetType(text).SetCollation("utf8mb4_bin"),
+				),
+			schema.NewTable("posts").
+				SetCharset("latin1").
+				SetCollation("latin1_bin").
+				AddColumns(
+					schema.NewColumn("a").SetType(text).SetCharset("latin1"),
+					schema.NewColumn("b").SetType(text).SetCharset("latin1").SetCollation("latin1_bin"),
+				),
+		)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	// The implied collation of a charset-only element is its charset default,
+	// and truly redundant charset and collation pairs are not printed.
+	const expected = `table "users" {
+  schema  = schema.test
+  charset = "utf8mb4"
+  column "a" {
+    null = false
+    type = text
+  }
+  column "b" {
+    null = false
+    type = text
+  }
+  column "c" {
+    null    = false
+    type    = text
+    collate = "utf8mb4_bin"
+  }
+}
+table "posts" {
+  schema  = schema.test
+  charset = "latin1"
+  collate = "latin1_bin"
+  column "a" {
+    null    = false
+    type    = text
+    charset = "latin1"
+  }
+  column "b" {
+    null = false
+    type = text
+  }
+}
+schema "test" {
+}
+`
+	require.Equal(t, expected, string(buf))
+
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	users, ok := s2.Table("users")
+	require.True(t, ok)
+	for c, want := range map[string][2]string{"a": {"utf8mb4", "utf8mb4_0900_ai_ci"}, "b": {"utf8mb4", "utf8mb4_0900_ai_ci"}, "c": {"utf8mb4", "utf8mb4_bin"}} {
+		col, ok := users.Column(c)
+		require.True(t, ok)
+		ch, co := ResolveCharset(col, users, &s2)
+		require.Equal(t, want, [2]string{ch, co}, c)
+	}
+	posts, ok := s2.Table("posts")
+	require.True(t, ok)
+	for c, want := range map[string][2]string{"a": {"latin1", "latin1_swedish_ci"}, "b": {"latin1", "latin1_bin"}} {
+		col, ok := posts.Column(c)
+		require.True(t, ok)
+		ch, co := ResolveCharset(col, posts, &s2)
+		require.Equal(t, want, [2]string{ch, co}, c)
+	}
+}
+
 func TestMarshalSpec_Comment(t *testing.T) {
 	s := &schema.Schema{
 		Name: "test",
@@ -512,7 +606,8 @@ func TestMarshalSpec_Check(t *testing.T) {
 				).
 				AddChecks(
 					schema.NewCheck().SetName("price1 positive").SetExpr("price1 > 0"),
-					schema.NewCheck().SetExpr("price1 <> price2").AddAttrs(&Enforced{}),
+					schema.NewCheck().SetExpr("price1 <> price2").AddAttrs(&Enforced{V: true}),
+					schema.NewCheck().SetName("price2 positive").SetExpr("price2 > 0").AddAttrs(&Enforced{V: false}),
 				),
 		)
 	buf, err := MarshalSpec(s, hclState)
@@ -534,11 +629,73 @@ func TestMarshalSpec_Check(t *testing.T) {
     expr     = "price1 <> price2"
     enforced = true
   }
+  check "price2 positive" {
+    expr     = "price2 > 0"
+    enforced = false
+  }
 }
 schema "test" {
 }
 `
 	require.EqualValues(t, expected, string(buf))
+
+	// NOT ENFORCED checks survive a round-trip.
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	checks := after.Tables[0].Attrs
+	require.Len(t, checks, 3)
+	require.Empty(t, checks[0].(*schema.Check).Attrs)
+	require.Equal(t, []schema.Attr{&Enforced{V: true}}, checks[1].(*schema.Check).Attrs)
+	require.Equal(t, []schema.Attr{&Enforced{V: false}}, checks[2].(*schema.Check).Attrs)
+	require.False(t, enforced(checks[2].(*schema.Check).Attrs))
+}
+
+func TestSpec_CheckComment(t *testing.T) {
+	f := `table "products" {
+  schema = schema.test
+  column "price" {
+    null = false
+    type = int
+  }
+  check "positive_price" {
+    expr    = "price > 0"
+    comment = "prices must be positive"
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	err := EvalHCLBytes([]byte(f), &s, nil)
+	require.EqualError(t, err, `check "positive_price": comments on CHECK constraints are not supported by MySQL or MariaDB`)
+}
+
+func TestSpec_CheckJSONSchema(t *testing.T) {
+	const expr = `json_schema_valid('{"$schema": "http://json-schema.org/draft-07/schema", "type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}', doc)`
+	var s schema.Schema
+	err := EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "doc" {
+    type = json
+  }
+  check "doc_schema" {
+    expr = "json_schema_valid('{\"$schema\": \"http://json-schema.org/draft-07/schema\", \"type\": \"object\", \"properties\": {\"id\": {\"type\": \"integer\"}}, \"required\": [\"id\"]}', doc)"
+  }
+}
+`), &s, nil)
+	require.NoError(t, err)
+	check := s.Tables[0].Attrs[0].(*schema.Check)
+	require.Equal(t, "doc_schema", check.Name)
+	require.Equal(t, expr, check.Expr)
+
+	// The expression survives a marshal/unmarshal round-trip unchanged.
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	require.Equal(t, expr, after.Tables[0].Attrs[0].(*schema.Check).Expr)
 }
 
 func TestUnmarshalSpec_IndexParts(t *testing.T) {
@@ -581,6 +738,45 @@ table "users" {
 		)
 	exp.Tables[0].Columns[0].Indexes = nil
 	require.EqualValues(t, exp, &s)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+	schema = schema.test
+	column "name" {
+		type = text
+	}
+	index "idx" {
+		on {
+			column = table.users.column.name
+		}
+		on {
+			expr   = "lower(name)"
+			prefix = 10
+		}
+	}
+}
+`), &s, nil)
+	require.EqualError(t, err, `index "idx": attribute "prefix" cannot be used in expression part "lower(name)" at position 1`)
+
+	for _, p := range []string{"0", "-1"} {
+		err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+	schema = schema.test
+	column "name" {
+		type = text
+	}
+	index "idx" {
+		on {
+			column = table.users.column.name
+			prefix = `+p+`
+		}
+	}
+}
+`), &s, nil)
+		require.EqualError(t, err, `index "idx": attribute "prefix" must be a positive number, got `+p+` at position 0 (omit it to index the full column)`)
+	}
 }
 
 func TestMarshalSpec_IndexParts(t *testing.T) {
@@ -604,7 +800,7 @@ func TestMarshalSpec_IndexParts(t *testing.T) {
 						AddParts(schema.NewColumnPart(c2)),
 				),
 		)
-	buf, err := MarshalHCL(s)
+	buf, err := MarshalHCL.MarshalSpec(s)
 	require.NoError(t, err)
 	exp := `table "users" {
   schema = schema.test
@@ -654,7 +850,7 @@ schema "test" {
 						),
 				),
 		)
-	buf, err = MarshalHCL(s)
+	buf, err = MarshalHCL.MarshalSpec(s)
 	require.NoError(t, err)
 	exp = `table "users" {
   schema = schema.test
@@ -829,6 +1025,63 @@ table "users" {
 	require.EqualValues(t, exp, &s)
 }
 
+func TestUnmarshalSpec_GeneratedColumnRefs(t *testing.T) {
+	var s schema.Schema
+	// Generated columns can reference base columns that are defined after them.
+	err := EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "c1" {
+    type = int
+    as   = "c2 * 2"
+  }
+  column "c2" {
+    type = int
+  }
+}
+`), &s, nil)
+	require.NoError(t, err)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "c1" {
+    type = int
+  }
+  column "c2" {
+    type = int
+    as   = "abs(`+"`c3`"+`) + c1"
+  }
+  column "c3" {
+    type = int
+    as   = "c1 * 2"
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `generated column "c2" of table "users" cannot reference generated column "c3" that is not defined before it`)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type           = int
+    auto_increment = true
+  }
+  column "c" {
+    type = int
+    as   = "users.id + 1"
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `generated column "c" of table "users" cannot reference auto-increment column "id"`)
+}
+
 func TestMarshalSpec_FloatUnsigned(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -914,6 +1167,21 @@ func TestTypes(t *testing.T) {
 			typeExpr: "bigint",
 			expected: &schema.IntegerType{T: TypeBigInt},
 		},
+		{
+			typeExpr:  "int",
+			extraAttr: "unsigned=true\nzerofill=true",
+			expected:  &schema.IntegerType{T: TypeInt, Unsigned: true, Attrs: []schema.Attr{&ZeroFill{A: "zerofill"}}},
+		},
+		{
+			typeExpr:  "int",
+			extraAttr: "zerofill=true",
+			expected:  &schema.IntegerType{T: TypeInt, Unsigned: true, Attrs: []schema.Attr{&ZeroFill{A: "zerofill"}}},
+		},
+		{
+			typeExpr:  "int(10)",
+			extraAttr: "zerofill=true",
+			expected:  &schema.IntegerType{T: TypeInt, Unsigned: true, Attrs: []schema.Attr{&DisplayWidth{N: 10}, &ZeroFill{A: "zerofill"}}},
+		},
 		{
 			typeExpr:  "bigint",
 			extraAttr: "unsigned=true",
@@ -976,23 +1244,23 @@ func TestTypes(t *testing.T) {
 		},
 		{
 			typeExpr: "int(10)",
-			expected: &schema.IntegerType{T: TypeInt},
+			expected: &schema.IntegerType{T: TypeInt, Attrs: []schema.Attr{&DisplayWidth{N: 10}}},
 		},
 		{
 			typeExpr: "tinyint(10)",
-			expected: &schema.IntegerType{T: TypeTinyInt},
+			expected: &schema.IntegerType{T: TypeTinyInt, Attrs: []schema.Attr{&DisplayWidth{N: 10}}},
 		},
 		{
 			typeExpr: "smallint(10)",
-			expected: &schema.IntegerType{T: TypeSmallInt},
+			expected: &schema.IntegerType{T: TypeSmallInt, Attrs: []schema.Attr{&DisplayWidth{N: 10}}},
 		},
 		{
 			typeExpr: "mediumint(10)",
-			expected: &schema.IntegerType{T: TypeMediumInt},
+			expected: &schema.IntegerType{T: TypeMediumInt, Attrs: []schema.Attr{&DisplayWidth{N: 10}}},
 		},
 		{
 			typeExpr: "bigint(10)",
-			expected: &schema.IntegerType{T: TypeBigInt},
+			expected: &schema.IntegerType{T: TypeBigInt, Attrs: []schema.Attr{&DisplayWidth{N: 10}}},
 		},
 		{
 			typeExpr: "decimal",
@@ -1208,7 +1476,7 @@ schema "test" {
 			require.NoError(t, err)
 			colspec := test.Tables[0].Columns[0]
 			require.EqualValues(t, tt.expected, colspec.Type.Type)
-			spec, err := MarshalHCL(&test)
+			spec, err := MarshalHCL.MarshalSpec(&test)
 			require.NoError(t, err)
 			var after schema.Schema
 			err = EvalHCLBytes(spec, &after, nil)
@@ -1344,7 +1612,7 @@ func TestMarshalRealm(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(
 		t,
-		`table "t1" {
+		`table "s1" "t1" {
   schema = schema.s1
   column "id" {
     null = false
@@ -1360,7 +1628,7 @@ table "s1" "t2" {
   }
   foreign_key "oid2id" {
     columns     = [column.oid]
-    ref_columns = [table.t1.column.id]
+    ref_columns = [table.s1.t1.column.id]
   }
 }
 table "t3" {
@@ -1390,7 +1658,7 @@ table "t5" {
   }
   foreign_key "oid2id1" {
     columns     = [column.oid]
-    ref_columns = [table.t1.column.id]
+    ref_columns = [table.s1.t1.column.id]
   }
   foreign_key "oid2id2" {
     columns     = [column.oid]
@@ -1404,3 +1672,3109 @@ schema "s2" {
 `,
 		string(got))
 }
+
+func TestMarshalRealm_CrossSchemaFK(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"))
+	posts := schema.NewTable("posts").
+		AddColumns(schema.NewIntColumn("author_id", "int"))
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	r := schema.NewRealm(
+		schema.New("s1").AddTables(users),
+		schema.New("s2").AddTables(posts),
+	)
+	got, err := MarshalHCL.MarshalSpec(r)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		`table "s1" "users" {
+  schema = schema.s1
+  column "id" {
+    null = false
+    type = int
+  }
+}
+table "posts" {
+  schema = schema.s2
+  column "author_id" {
+    null = false
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.s1.users.column.id]
+  }
+}
+schema "s1" {
+}
+schema "s2" {
+}
+`,
+		string(got))
+
+	var r1 schema.Realm
+	require.NoError(t, EvalHCLBytes(got, &r1, nil))
+	s2, ok := r1.Schema("s2")
+	require.True(t, ok)
+	fk, ok := s2.Tables[0].ForeignKey("author")
+	require.True(t, ok)
+	require.Equal(t, "users", fk.RefTable.Name)
+	require.Equal(t, "s1", fk.RefTable.Schema.Name)
+	require.Equal(t, "id", fk.RefColumns[0].Name)
+}
+
+func TestMarshalRealm_RoundTrip(t *testing.T) {
+	users1 := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"))
+	users1.SetPrimaryKey(schema.NewPrimaryKey(users1.Columns[0]))
+	users2 := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("ref_id", "int"))
+	users2.AddForeignKeys(schema.NewForeignKey("ref").AddColumns(users2.Columns[1]).SetRefTable(users1).AddRefColumns(users1.Columns[0]))
+	posts := schema.NewTable("posts").
+		AddColumns(schema.NewIntColumn("author_id", "int"))
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[0]).SetRefTable(users2).AddRefColumns(users2.Columns[0]))
+	r := schema.NewRealm(
+		schema.New("s1").AddTables(users1),
+		schema.New("s2").AddTables(users2, posts),
+	)
+	buf, err := MarshalHCL.MarshalSpec(r)
+	require.NoError(t, err)
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Schemas, 2)
+	for i, s := range r.Schemas {
+		require.Equal(t, s.Name, got.Schemas[i].Name)
+		require.Len(t, got.Schemas[i].Tables, len(s.Tables))
+		for j, t1 := range s.Tables {
+			t2 := got.Schemas[i].Tables[j]
+			require.Equal(t, t1.Name, t2.Name)
+			require.Equal(t, s.Name, t2.Schema.Name)
+			require.Len(t, t2.Columns, len(t1.Columns))
+			require.Len(t, t2.ForeignKeys, len(t1.ForeignKeys))
+			for k, fk := range t1.ForeignKeys {
+				require.Equal(t, fk.Symbol, t2.ForeignKeys[k].Symbol)
+				require.Equal(t, fk.RefTable.Name, t2.ForeignKeys[k].RefTable.Name)
+				require.Equal(t, fk.RefTable.Schema.Name, t2.ForeignKeys[k].RefTable.Schema.Name)
+			}
+		}
+	}
+	// Same-named tables are resolved to their schema.
+	fk := got.Schemas[1].Tables[0].ForeignKeys[0]
+	require.Same(t, got.Schemas[0].Tables[0], fk.RefTable)
+	require.Same(t, got.Schemas[0].Tables[0].Columns[0], fk.RefColumns[0])
+	fk = got.Schemas[1].Tables[1].ForeignKeys[0]
+	require.Same(t, got.Schemas[1].Tables[0], fk.RefTable)
+
+	// Marshaling the parsed realm produces the same document.
+	buf1, err := MarshalHCL.MarshalSpec(&got)
+	require.NoError(t, err)
+	require.Equal(t, string(buf), string(buf1))
+}
+
+func TestEvalHCLContext(t *testing.T) {
+	f := []byte(`
+schema "s" {}
+table "t1" {
+  schema = schema.s
+  column "id" {
+    type = int
+  }
+}
+`)
+	var s schema.Schema
+	require.NoError(t, EvalHCLContext(context.Background(), f, &s, nil))
+	require.Len(t, s.Tables, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var r schema.Realm
+	err := EvalHCLContext(ctx, f, &r, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestUnmarshalHCLReader(t *testing.T) {
+	var s schema.Schema
+	require.NoError(t, UnmarshalHCLReader(strings.NewReader(`
+schema "s" {}
+table "t1" {
+  schema = schema.s
+  column "id" {
+    type = int
+  }
+}
+`), &s))
+	require.Equal(t, "s", s.Name)
+	require.Len(t, s.Tables, 1)
+	require.Equal(t, "id", s.Tables[0].Columns[0].Name)
+
+	err := UnmarshalHCLReader(iotest.ErrReader(io.ErrUnexpectedEOF), &s)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestEvalHCLWith_AllErrors(t *testing.T) {
+	f := []byte(`
+schema "s" {}
+table "t1" {
+  schema = schema.s
+  column "a" {
+    type = int
+    auto_increment = "yes"
+  }
+  column "b" {
+    type = timestamp
+    on_update = 1
+  }
+  column "c" {
+    type = int
+  }
+}
+table "t2" {
+  schema = schema.s
+  column "a" {
+    type = int
+    charset = 1
+  }
+  index "idx" {
+    on {
+      expr   = "a + 1"
+      prefix = 10
+    }
+  }
+}
+table "t3" {
+  schema      = schema.s
+  encryption  = "yes"
+  compression = "zstd"
+  column "a" {
+    type = varchar(255)
+  }
+  primary_key {
+    on {
+      column = column.a
+      prefix = 0
+    }
+  }
+}
+table "t4" {
+  schema = schema.s
+  column "a" {
+    type = int
+  }
+  column "b" {
+    type = int
+  }
+  foreign_key "fk" {
+    columns     = [column.a, column.b]
+    ref_columns = [table.t1.column.c]
+  }
+}
+`)
+	var r schema.Realm
+	err := EvalHCLBytes(f, &r, nil)
+	require.Error(t, err)
+	_, ok := err.(interface{ Errors() []error })
+	require.False(t, ok, "expect first error only")
+
+	evalAll := specutil.HCLBytesFunc(EvalHCLWith(WithAllErrors()))
+	err = evalAll(f, &r, nil)
+	require.Error(t, err)
+	var all interface{ Errors() []error }
+	require.ErrorAs(t, err, &all)
+	errs := all.Errors()
+	require.Len(t, errs, 8)
+	require.Contains(t, errs[0].Error(), `table "t1": column "a"`)
+	require.Contains(t, errs[1].Error(), `table "t1": column "b"`)
+	require.Contains(t, errs[2].Error(), `table "t2": column "a"`)
+	require.Contains(t, errs[3].Error(), `table "t2": index "idx"`)
+	require.EqualError(t, errs[4], `table "t3": primary key: index "PRIMARY": attribute "prefix" must be a positive number, got 0 at position 0 (omit it to index the full column)`)
+	require.EqualError(t, errs[5], `table "t3": invalid encryption value "yes", expected "Y" or "N"`)
+	require.EqualError(t, errs[6], `table "t3": invalid compression value "zstd", expected "zlib", "lz4" or "none"`)
+	require.EqualError(t, errs[7], `table "t4": sqlspec: number of referencing and referenced columns do not match for foreign-key "fk"`)
+
+	// Valid documents are evaluated as usual.
+	var s schema.Schema
+	require.NoError(t, evalAll([]byte(`
+schema "s" {}
+table "t" {
+  schema = schema.s
+  column "a" {
+    type = int
+  }
+}
+`), &s, nil))
+	require.Len(t, s.Tables, 1)
+}
+
+func TestSpec_AutoIncrementUniqueKey(t *testing.T) {
+	const f = `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = varchar(36)
+  }
+  column "seq" {
+    null           = false
+    type           = bigint
+    auto_increment = true
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "seq" {
+    unique  = true
+    columns = [column.seq]
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	seq, ok := users.Column("seq")
+	require.True(t, ok)
+	require.True(t, sqlx.Has(seq.Attrs, &AutoIncrement{}))
+	require.NotEqual(t, seq, users.PrimaryKey.Parts[0].C)
+	idx, ok := users.Index("seq")
+	require.True(t, ok)
+	require.True(t, idx.Unique)
+	require.Equal(t, seq, idx.Parts[0].C)
+
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	drv, _, err := newMigrate("8.0.13")
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: users}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, "CREATE TABLE `test`.`users` (`id` varchar(36) NOT NULL, `seq` bigint NOT NULL AUTO_INCREMENT, PRIMARY KEY (`id`), UNIQUE INDEX `seq` (`seq`))", plan.Changes[0].Cmd)
+}
+
+func TestMarshalSpec_ZeroFill(t *testing.T) {
+	typ, err := ParseType("int unsigned zerofill")
+	require.NoError(t, err)
+	require.Equal(t, &schema.IntegerType{T: TypeInt, Unsigned: true, Attrs: []schema.Attr{&ZeroFill{A: "zerofill"}}}, typ)
+	f, err := FormatType(typ)
+	require.NoError(t, err)
+	require.Equal(t, "int unsigned zerofill", f)
+
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("t").
+				AddColumns(
+					schema.NewColumn("a").SetType(typ),
+					schema.NewColumn("b").SetType(&schema.IntegerType{T: TypeBigInt, Unsigned: true, Attrs: []schema.Attr{&DisplayWidth{N: 20}, &ZeroFill{A: "zerofill"}}}),
+				),
+		)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "t" {
+  schema = schema.test
+  column "a" {
+    null     = false
+    type     = int
+    unsigned = true
+    zerofill = true
+  }
+  column "b" {
+    null     = false
+    type     = bigint(20)
+    unsigned = true
+    zerofill = true
+  }
+}
+schema "test" {
+}
+`
+	require.Equal(t, expected, string(buf))
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	require.Equal(t, s.Tables[0].Columns[0].Type.Type, after.Tables[0].Columns[0].Type.Type)
+	require.Equal(t, s.Tables[0].Columns[1].Type.Type, after.Tables[0].Columns[1].Type.Type)
+}
+
+func TestDiff_DisplayWidth(t *testing.T) {
+	var from, to schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "a" {
+    type = int(11)
+  }
+}
+`), &from, nil))
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "a" {
+    type = int
+  }
+}
+`), &to, nil))
+	require.Equal(t, &schema.IntegerType{T: TypeInt, Attrs: []schema.Attr{&DisplayWidth{N: 11}}}, from.Tables[0].Columns[0].Type.Type)
+	require.Equal(t, &schema.IntegerType{T: TypeInt}, to.Tables[0].Columns[0].Type.Type)
+	buf, err := MarshalHCL.MarshalSpec(&from)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), "type = int(11)")
+	buf, err = MarshalHCL.MarshalSpec(&to)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), "type = int\n")
+
+	for _, v := range []string{"5.7.8", "8.0.13", "8.0.19"} {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.version(v)
+		drv, err := Open(db)
+		require.NoError(t, err)
+		changes, err := drv.TableDiff(from.Tables[0], to.Tables[0])
+		require.NoError(t, err)
+		require.Empty(t, changes, "display width should not affect diff on %s", v)
+	}
+}
+
+func TestMarshalHCLWith_Header(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("t").AddColumns(schema.NewIntColumn("id", TypeInt)),
+		)
+	buf, err := MarshalHCLWith(schemahcl.WithHeader("Generated by Atlas; do not edit")).MarshalSpec(s)
+	require.NoError(t, err)
+	require.Equal(t, `// Generated by Atlas; do not edit
+table "t" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	require.Equal(t, "test", after.Name)
+	require.Len(t, after.Tables, 1)
+}
+
+func TestMarshalHCLWith_Indent(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("t").
+				AddColumns(schema.NewIntColumn("id", TypeInt)).
+				AddIndexes(schema.NewIndex("id").AddColumns(schema.NewColumn("id"))),
+		)
+	spaces, err := MarshalHCL.MarshalSpec(s)
+	require.NoError(t, err)
+	tabs, err := MarshalHCLWith(schemahcl.WithIndent("\t")).MarshalSpec(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "t" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  index "id" {
+    columns = [column.id]
+  }
+}
+schema "test" {
+}
+`, string(spaces))
+	require.Equal(t, strings.ReplaceAll(string(spaces), "  ", "\t"), string(tabs))
+
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(tabs, &after, nil))
+	require.Equal(t, "id", after.Tables[0].Indexes[0].Name)
+}
+
+func TestMarshalHCLWith_QuotedRefs(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", TypeInt))
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns...))
+	posts := schema.NewTable("posts").
+		AddColumns(schema.NewIntColumn("author_id", TypeInt))
+	posts.AddIndexes(schema.NewIndex("author").AddColumns(posts.Columns...))
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns...).SetRefTable(users).AddRefColumns(users.Columns...))
+	s := schema.New("test").AddTables(users, posts)
+	buf, err := MarshalHCLWith(schemahcl.WithQuotedRefs()).MarshalSpec(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema["test"]
+  column "id" {
+    null = false
+    type = int
+  }
+  primary_key {
+    columns = [column["id"]]
+  }
+}
+table "posts" {
+  schema = schema["test"]
+  column "author_id" {
+    null = false
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column["author_id"]]
+    ref_columns = [table["users"].column["id"]]
+  }
+  index "author" {
+    columns = [column["author_id"]]
+  }
+}
+schema "test" {
+}
+`, string(buf))
+
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	buf2, err := MarshalHCL.MarshalSpec(&after)
+	require.NoError(t, err)
+	expected, err := MarshalHCL.MarshalSpec(s)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(buf2))
+}
+
+func TestMarshalHCLWith_Newline(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", TypeInt).SetComment("multi\nline"))
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns...))
+	s := schema.New("test").AddTables(users)
+	lf, err := MarshalHCL.MarshalSpec(s)
+	require.NoError(t, err)
+	crlf, err := MarshalHCLWith(schemahcl.WithNewline("\r\n")).MarshalSpec(s)
+	require.NoError(t, err)
+	require.Equal(t, strings.ReplaceAll(string(lf), "\n", "\r\n"), string(crlf))
+
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(crlf, &after, nil))
+	buf, err := MarshalHCL.MarshalSpec(&after)
+	require.NoError(t, err)
+	require.Equal(t, string(lf), string(buf))
+}
+
+func TestMarshalJSON(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(
+			schema.NewIntColumn("id", TypeInt),
+			schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)).SetNull(true).SetDefault(&schema.RawExpr{X: "(uuid())"}),
+		)
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	users.AddIndexes(schema.NewUniqueIndex("name").AddColumns(users.Columns[1]))
+	s := schema.New("test").AddTables(users)
+	buf, err := MarshalJSON(s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "blocks": [
+    {
+      "type": "table",
+      "name": "users",
+      "attrs": {"schema": {"$ref": "$schema.test"}},
+      "blocks": [
+        {"type": "column", "name": "id", "attrs": {"null": false, "type": {"$type": "int"}}},
+        {"type": "column", "name": "name", "attrs": {"null": true, "type": {"$type": "varchar(255)"}, "default": {"$expr": "(uuid())"}}},
+        {"type": "primary_key", "attrs": {"columns": [{"$ref": "$column.id"}]}},
+        {"type": "index", "name": "name", "attrs": {"unique": true, "columns": [{"$ref": "$column.name"}]}}
+      ]
+    },
+    {"type": "schema", "name": "test"}
+  ]
+}`, string(buf))
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var s1 schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {
+  charset = "utf8mb4"
+  collate = "utf8mb4_0900_ai_ci"
+}
+table "users" {
+  schema  = schema.test
+  comment = "users table"
+  charset = "utf8mb4"
+  collate = "utf8mb4_bin"
+  column "id" {
+    type           = bigint
+    unsigned       = true
+    auto_increment = true
+  }
+  column "name" {
+    type    = varchar(255)
+    null    = true
+    default = "unknown"
+    charset = "latin1"
+    collate = "latin1_swedish_ci"
+  }
+  column "status" {
+    type    = enum("active", "inactive")
+    default = "active"
+  }
+  column "created_at" {
+    type    = timestamp(6)
+    default = sql("CURRENT_TIMESTAMP(6)")
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "name" {
+    on {
+      column = column.name
+      prefix = 10
+    }
+    on {
+      expr = "lower(status)"
+    }
+  }
+  check "positive_id" {
+    expr = "id > 0"
+  }
+}
+table "posts" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  column "author_id" {
+    type = bigint
+    unsigned = true
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+    on_delete   = CASCADE
+  }
+}
+`), &s1, nil))
+	buf, err := MarshalJSON(&s1)
+	require.NoError(t, err)
+	var s2 schema.Schema
+	require.NoError(t, UnmarshalJSON(buf, &s2))
+	require.Equal(t, s2.Tables[0], s2.Tables[1].ForeignKeys[0].RefTable)
+	require.Equal(t, s1.Tables[0].Columns, s2.Tables[0].Columns)
+	hcl1, err := MarshalHCL.MarshalSpec(&s1)
+	require.NoError(t, err)
+	hcl2, err := MarshalHCL.MarshalSpec(&s2)
+	require.NoError(t, err)
+	require.Equal(t, string(hcl1), string(hcl2))
+
+	var r schema.Realm
+	require.NoError(t, UnmarshalJSON(buf, &r))
+	require.Len(t, r.Schemas, 1)
+	require.Len(t, r.Schemas[0].Tables, 2)
+	require.Error(t, UnmarshalJSON([]byte(`{"blocks": [{"type": "schema"`), &s2))
+}
+
+func TestMarshalHCLWith_WithoutCosmetics(t *testing.T) {
+	newSchema := func(comment, charset string) *schema.Schema {
+		return schema.New("test").
+			AddAttrs(&schema.Charset{V: charset}).
+			AddTables(
+				schema.NewTable("t").
+					SetComment(comment).
+					AddAttrs(&schema.Charset{V: charset}, &schema.Collation{V: charset + "_bin"}).
+					AddColumns(
+						schema.NewIntColumn("id", TypeInt).SetComment(comment),
+						schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)).
+							SetCharset(charset).
+							SetCollation(charset+"_bin"),
+					).
+					AddIndexes(
+						schema.NewIndex("name").AddColumns(schema.NewColumn("name")).AddAttrs(&schema.Comment{Text: comment}),
+					),
+			)
+	}
+	s1, s2 := newSchema("first", "utf8mb4"), newSchema("second", "latin1")
+	b1, err := MarshalHCL.MarshalSpec(s1)
+	require.NoError(t, err)
+	b2, err := MarshalHCL.MarshalSpec(s2)
+	require.NoError(t, err)
+	require.NotEqual(t, string(b1), string(b2))
+
+	m := MarshalHCLWith(WithoutCosmetics())
+	b1, err = m.MarshalSpec(s1)
+	require.NoError(t, err)
+	b2, err = m.MarshalSpec(s2)
+	require.NoError(t, err)
+	require.Equal(t, string(b1), string(b2))
+	require.Equal(t, `table "t" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  column "name" {
+    null = false
+    type = varchar(255)
+  }
+  index "name" {
+    columns = [column.name]
+  }
+}
+schema "test" {
+}
+`, string(b1))
+}
+
+func TestSpec_ExprDefault(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `table "t" {
+  schema = schema.test
+  column "j" {
+    null    = false
+    type    = json
+    default = sql("('{}')")
+  }
+  column "b" {
+    null    = false
+    type    = text
+    default = sql("('hello')")
+  }
+}
+schema "test" {
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, &schema.RawExpr{X: "('{}')"}, s.Tables[0].Columns[0].Default)
+	require.Equal(t, &schema.RawExpr{X: "('hello')"}, s.Tables[0].Columns[1].Default)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`j` json NOT NULL DEFAULT ('{}'), `b` text NOT NULL DEFAULT ('hello'))", plan.Changes[0].Cmd)
+
+	// Inspected expression defaults contain charset introducers.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(
+			schema.NewJSONColumn("j", TypeJSON).SetDefault(&schema.RawExpr{X: "(_utf8mb4'{}')"}),
+			schema.NewStringColumn("b", TypeText).SetDefault(&schema.RawExpr{X: "(_utf8mb4'hello')"}),
+		)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.19")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	changes, err := drv.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestNormalizeHCL(t *testing.T) {
+	f := `
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" { type = int }
+  column "name" {
+    type = varchar(255)
+    null = true
+  }
+  primary_key { columns = [column.id] }
+}
+`
+	norm, err := NormalizeHCL([]byte(f))
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  column "name" {
+    null = true
+    type = varchar(255)
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+schema "test" {
+}
+`, string(norm))
+	// Normalized documents are a fixed point.
+	again, err := NormalizeHCL(norm)
+	require.NoError(t, err)
+	require.Equal(t, string(norm), string(again))
+
+	_, err = NormalizeHCL([]byte(`table "t" {`))
+	require.Error(t, err)
+}
+
+func TestEvalHCLWith_UnknownAttrs(t *testing.T) {
+	f := `table "users" {
+  schema = schema.test
+  foo    = "bar"
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Empty(t, s.Tables[0].Attrs, "unknown attributes are dropped by default")
+
+	require.NoError(t, specutil.HCLBytesFunc(EvalHCLWith(WithUnknownAttrs()))([]byte(f), &s, nil))
+	require.Len(t, s.Tables[0].Attrs, 1)
+	u, ok := s.Tables[0].Attrs[0].(*UnknownAttr)
+	require.True(t, ok)
+	require.Equal(t, "foo", u.A.K)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	// Unknown attributes are ignored by diffing.
+	var to schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &to, nil))
+	changes, err := DefaultDiff.TableDiff(s.Tables[0], to.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestEvalHCLWith_UnknownColumnAttrs(t *testing.T) {
+	f := `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+    foo  = 1
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Empty(t, s.Tables[0].Columns[0].Attrs, "unknown attributes are dropped by default")
+
+	require.NoError(t, specutil.HCLBytesFunc(EvalHCLWith(WithUnknownAttrs()))([]byte(f), &s, nil))
+	require.Empty(t, s.Tables[0].Attrs)
+	require.Len(t, s.Tables[0].Columns[0].Attrs, 1)
+	u, ok := s.Tables[0].Columns[0].Attrs[0].(*UnknownAttr)
+	require.True(t, ok)
+	require.Equal(t, "foo", u.A.K)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	// Unknown attributes are ignored by diffing.
+	var to schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &to, nil))
+	changes, err := DefaultDiff.TableDiff(s.Tables[0], to.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_IndexHint(t *testing.T) {
+	f := `table "users" {
+  schema = schema.test
+  column "name" {
+    null = false
+    type = varchar(255)
+  }
+  index "name" {
+    columns = [column.name]
+    hint {
+      owner = "search"
+      use   = "FORCE INDEX"
+    }
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	h := &IndexHint{}
+	require.True(t, sqlx.Has(s.Tables[0].Indexes[0].Attrs, h))
+	require.Equal(t, "hint", h.R.Type)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	// Hints are ignored by diffing.
+	to := schema.NewTable("users").
+		SetSchema(schema.New("test")).
+		AddColumns(schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)))
+	to.AddIndexes(schema.NewIndex("name").AddColumns(to.Columns[0]))
+	changes, err := DefaultDiff.TableDiff(s.Tables[0], to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpecTo(t *testing.T) {
+	r := schema.NewRealm()
+	for i := 0; i < 3; i++ {
+		s := schema.New(fmt.Sprintf("s%d", i))
+		for j := 0; j < 50; j++ {
+			s.AddTables(
+				schema.NewTable(fmt.Sprintf("t%d", j)).
+					AddColumns(
+						schema.NewIntColumn("id", TypeBigInt),
+						schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)),
+					),
+			)
+		}
+		r.AddSchemas(s)
+	}
+	// Reference a table in another schema.
+	users, posts := r.Schemas[0].Tables[0], r.Schemas[1].Tables[0]
+	posts.AddColumns(schema.NewIntColumn("user_id", TypeBigInt))
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[2]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+
+	expected, err := MarshalSpec(r, hclState)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, MarshalSpecTo(&buf, r, hclState))
+	require.Equal(t, string(expected), buf.String())
+	buf.Reset()
+	require.NoError(t, MarshalSpecTo(&buf, r, MarshalHCL))
+	require.Equal(t, string(expected), buf.String())
+
+	var after schema.Realm
+	require.NoError(t, EvalHCLBytes(buf.Bytes(), &after, nil))
+	require.Len(t, after.Schemas, 3)
+
+	// Configured marshalers write their header only once.
+	opts := []schemahcl.Option{schemahcl.WithHeader("Generated by Atlas; do not edit"), schemahcl.WithIndent("\t")}
+	expected, err = MarshalHCLWith(opts...).MarshalSpec(r)
+	require.NoError(t, err)
+	for _, m := range []schemahcl.Marshaler{MarshalHCLWith(opts...), schemahcl.New(append(hclOptions(), opts...)...)} {
+		buf.Reset()
+		require.NoError(t, MarshalSpecTo(&buf, r, m))
+		require.Equal(t, string(expected), buf.String())
+		require.Equal(t, 1, strings.Count(buf.String(), "// Generated by Atlas; do not edit"))
+	}
+}
+
+func TestSpec_SetEnumValuesLimit(t *testing.T) {
+	doc := func(typ string, n int) []byte {
+		vs := make([]string, n)
+		for i := range vs {
+			vs[i] = fmt.Sprintf("%q", fmt.Sprintf("v%d", i))
+		}
+		return []byte(fmt.Sprintf(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "c" {
+    type = %s(%s)
+  }
+}
+`, typ, strings.Join(vs, ", ")))
+	}
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes(doc("set", 64), &s, nil))
+	require.Len(t, s.Tables[0].Columns[0].Type.Type.(*SetType).Values, 64)
+	err := EvalHCLBytes(doc("set", 65), &s, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "set type has 65 values, exceeding the maximum of 64")
+
+	err = EvalHCLBytes(doc("enum", 65536), &s, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "enum type has 65536 values, exceeding the maximum of 65535")
+}
+
+func TestSpec_ColumnPosition(t *testing.T) {
+	f := `table "users" {
+  schema = schema.test
+  column "id" {
+    null  = false
+    type  = int
+    first = true
+  }
+  column "name" {
+    null  = false
+    type  = varchar(255)
+    after = "id"
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&ColumnPosition{First: true}}, s.Tables[0].Columns[0].Attrs)
+	require.Equal(t, []schema.Attr{&ColumnPosition{After: "id"}}, s.Tables[0].Columns[1].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{
+		&schema.ModifyTable{
+			T: s.Tables[0],
+			Changes: []schema.Change{
+				&schema.AddColumn{C: s.Tables[0].Columns[0]},
+				&schema.AddColumn{C: s.Tables[0].Columns[1]},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ALTER TABLE `test`.`users` ADD COLUMN `id` int NOT NULL FIRST, ADD COLUMN `name` varchar(255) NOT NULL AFTER `id`", plan.Changes[0].Cmd)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type  = int
+    first = true
+    after = "name"
+  }
+}
+`), &s, nil)
+	require.ErrorContains(t, err, `attributes "first" and "after" are mutually exclusive`)
+}
+
+func TestEvalHCLFiles(t *testing.T) {
+	var s schema.Schema
+	err := EvalHCLFiles(map[string][]byte{
+		"users.hcl": []byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+`),
+		"posts.hcl": []byte(`
+table "posts" {
+  schema = schema.test
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+`),
+	}, &s)
+	require.NoError(t, err)
+	require.Equal(t, "test", s.Name)
+	require.Len(t, s.Tables, 2)
+	posts, ok := s.Table("posts")
+	require.True(t, ok)
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, users, posts.ForeignKeys[0].RefTable)
+
+	err = EvalHCLFiles(map[string][]byte{
+		"schema.hcl": []byte(`schema "test" {}`),
+		"users.hcl":  []byte(`table "users" {`),
+	}, &s)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "users.hcl")
+}
+
+func TestSpec_SetNullNotNullColumn(t *testing.T) {
+	f := `
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+table "posts" {
+  schema = schema.test
+  column "author_id" {
+    type = int
+    null = %t
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+    on_delete   = SET_NULL
+  }
+}
+`
+	var s schema.Schema
+	err := EvalHCLBytes([]byte(fmt.Sprintf(f, false)), &s, nil)
+	require.EqualError(t, err, `foreign key "author" of table "posts" uses SET NULL, but column "author_id" is not nullable`)
+	var r schema.Realm
+	err = EvalHCLBytes([]byte(fmt.Sprintf(f, false)), &r, nil)
+	require.Error(t, err)
+	require.NoError(t, EvalHCLBytes([]byte(fmt.Sprintf(f, true)), &s, nil))
+}
+
+func TestSpec_AutoRandom(t *testing.T) {
+	f := `table "users" {
+  schema = schema.test
+  column "id" {
+    null        = false
+    type        = bigint
+    auto_random = 5
+  }
+  column "seq" {
+    null        = false
+    type        = bigint
+    auto_random = true
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&AutoRandom{ShardBits: 5}}, s.Tables[0].Columns[0].Attrs)
+	require.Equal(t, []schema.Attr{&AutoRandom{}}, s.Tables[0].Columns[1].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("5.7.25-TiDB-v6.1.0")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`users` (`id` bigint NOT NULL AUTO_RANDOM(5), `seq` bigint NOT NULL AUTO_RANDOM, PRIMARY KEY (`id`))", plan.Changes[0].Cmd)
+	for _, v := range []string{"8.0.19", "10.5.8-MariaDB"} {
+		pl, _, err := newMigrate(v)
+		require.NoError(t, err)
+		_, err = pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+		require.EqualError(t, err, `create table "users": column "id": AUTO_RANDOM is supported only by TiDB, column "seq": AUTO_RANDOM is supported only by TiDB`)
+	}
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type           = bigint
+    auto_increment = true
+    auto_random    = 5
+  }
+}
+`), &s, nil)
+	require.ErrorContains(t, err, `attributes "auto_increment" and "auto_random" are mutually exclusive`)
+}
+
+func TestParseType_Unknown(t *testing.T) {
+	for input, msg := range map[string]string{
+		"varchart(255)": `mysql: unknown type "varchart"; did you mean "varchar"?`,
+		"bigin":         `mysql: unknown type "bigin"; did you mean "bigint"?`,
+		"tinytxt":       `mysql: unknown type "tinytxt"; did you mean "tinytext"?`,
+		"datetim(6)":    `mysql: unknown type "datetim"; did you mean "datetime"?`,
+		"jsonb":         `mysql: unknown type "jsonb"; did you mean "json"?`,
+		"hstore":        `mysql: unknown type "hstore"`,
+	} {
+		typ, err := ParseType(input)
+		require.Nil(t, typ)
+		require.EqualError(t, err, msg, input)
+	}
+	typ, err := ParseType("varchar(255)")
+	require.NoError(t, err)
+	require.Equal(t, &schema.StringType{T: TypeVarchar, Size: 255}, typ)
+}
+
+func TestFormatType_OmitDefaults(t *testing.T) {
+	for _, tt := range []struct {
+		typ        schema.Type
+		full, omit string
+	}{
+		{typ: &schema.DecimalType{T: TypeDecimal}, full: "decimal(10)", omit: "decimal"},
+		{typ: &schema.DecimalType{T: TypeDecimal, Precision: 10}, full: "decimal(10)", omit: "decimal"},
+		{typ: &schema.DecimalType{T: TypeNumeric, Unsigned: true}, full: "decimal(10) unsigned", omit: "decimal unsigned"},
+		{typ: &schema.DecimalType{T: TypeDecimal, Precision: 12, Scale: 4}, full: "decimal(12,4)", omit: "decimal(12,4)"},
+		{typ: &schema.DecimalType{T: TypeDecimal, Precision: 12}, full: "decimal(12)", omit: "decimal(12)"},
+		{typ: &schema.StringType{T: TypeChar, Size: 2}, full: "char(2)", omit: "char(2)"},
+		{typ: &schema.StringType{T: TypeVarchar, Size: 255}, full: "varchar(255)", omit: "varchar(255)"},
+		{typ: &BitType{T: TypeBit, Size: 1}, full: "bit", omit: "bit"},
+	} {
+		f, err := FormatType(tt.typ)
+		require.NoError(t, err)
+		require.Equal(t, tt.full, f)
+		f, err = FormatType(tt.typ, OmitDefaults())
+		require.NoError(t, err)
+		require.Equal(t, tt.omit, f)
+		// Omitted defaults do not change the parsed type.
+		t1, err := ParseType(tt.full)
+		require.NoError(t, err)
+		t2, err := ParseType(tt.omit)
+		require.NoError(t, err)
+		changed, err := (&diff{}).typeChanged(&schema.Column{Type: &schema.ColumnType{Type: t1}}, &schema.Column{Type: &schema.ColumnType{Type: t2}})
+		require.NoError(t, err)
+		require.False(t, changed, tt.full)
+	}
+	_, err := FormatType(&schema.UnsupportedType{T: "custom"}, OmitDefaults())
+	require.Error(t, err)
+}
+
+func TestMarshalHCLWith_FormatOptions(t *testing.T) {
+	s := schema.New("test").AddTables(
+		schema.NewTable("t").AddColumns(
+			schema.NewColumn("a").SetType(&schema.DecimalType{T: TypeDecimal, Precision: 10}),
+			schema.NewColumn("b").SetType(&schema.DecimalType{T: TypeDecimal, Precision: 12, Scale: 4}),
+			schema.NewColumn("c").SetType(&schema.DecimalType{T: TypeNumeric, Precision: 10, Unsigned: true}),
+			schema.NewColumn("d").SetType(&schema.StringType{T: TypeVarchar, Size: 255}),
+		),
+	)
+	buf, err := MarshalHCLWith(WithFormatOptions(OmitDefaults())).MarshalSpec(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "t" {
+  schema = schema.test
+  column "a" {
+    null = false
+    type = decimal
+  }
+  column "b" {
+    null     = false
+    type     = decimal(12,4)
+    unsigned = false
+  }
+  column "c" {
+    null     = false
+    type     = numeric
+    unsigned = true
+  }
+  column "d" {
+    null = false
+    type = varchar(255)
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	var buf2 bytes.Buffer
+	require.NoError(t, MarshalSpecTo(&buf2, s, MarshalHCLWith(WithFormatOptions(OmitDefaults()))))
+	require.Equal(t, string(buf), buf2.String())
+
+	// Default sizes are kept without the option.
+	buf, err = MarshalHCL.MarshalSpec(s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), "type     = decimal(10)")
+	require.Contains(t, string(buf), "type     = numeric(10)")
+}
+
+func TestSpec_SRID(t *testing.T) {
+	f := `table "places" {
+  schema = schema.test
+  column "location" {
+    null = false
+    type = point
+    srid = 4326
+  }
+  column "area" {
+    null = true
+    type = polygon
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&SRID{ID: 4326}}, s.Tables[0].Columns[0].Attrs)
+	require.Empty(t, s.Tables[0].Columns[1].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`places` (`location` point NOT NULL SRID 4326, `area` polygon NULL)", plan.Changes[0].Cmd)
+
+	// SRID changes are detected by the diff.
+	to := schema.NewTable("places").
+		SetSchema(schema.New("test")).
+		AddColumns(
+			schema.NewSpatialColumn("location", TypePoint).AddAttrs(&SRID{ID: 3857}),
+			schema.NewNullSpatialColumn("area", TypePolygon),
+		)
+	changes, err := DefaultDiff.TableDiff(s.Tables[0], to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.True(t, changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeType))
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "c" {
+    type = int
+    srid = 4326
+  }
+}
+`), &s, nil)
+	require.ErrorContains(t, err, `attribute "srid" is supported only for spatial types`)
+}
+
+func TestSpec_TimePrecisionMismatch(t *testing.T) {
+	doc := func(typ, def, onUpdate string) []byte {
+		return []byte(fmt.Sprintf(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "c" {
+    type      = %s
+    default   = sql(%q)
+    on_update = sql(%q)
+  }
+}
+`, typ, def, onUpdate))
+	}
+	var s schema.Schema
+	err := EvalHCLBytes(doc("timestamp(3)", "current_timestamp(3)", "current_timestamp(6)"), &s, nil)
+	require.EqualError(t, err, `column "c": on_update precision 6 (current_timestamp(6)) does not match type precision 3 (timestamp)`)
+	err = EvalHCLBytes(doc("datetime", "CURRENT_TIMESTAMP(6)", "CURRENT_TIMESTAMP"), &s, nil)
+	require.EqualError(t, err, `column "c": default precision 6 (CURRENT_TIMESTAMP(6)) does not match type precision 0 (datetime)`)
+
+	require.NoError(t, EvalHCLBytes(doc("timestamp(3)", "current_timestamp(3)", "current_timestamp(3)"), &s, nil))
+	require.NoError(t, EvalHCLBytes(doc("timestamp", "CURRENT_TIMESTAMP", "now()"), &s, nil))
+	require.NoError(t, EvalHCLBytes(doc("timestamp(6)", "now(6)", "current_timestamp(6)"), &s, nil))
+}
+
+func TestScopedEnums(t *testing.T) {
+	enums := ScopedEnums()
+	require.Equal(t, []string{IndexTypeBTree, IndexTypeHash, IndexTypeFullText, IndexTypeSpatial}, enums["table.index.type"])
+	require.Equal(t, []string{"STORED", "PERSISTENT", "VIRTUAL"}, enums["table.column.as.type"])
+	require.Contains(t, enums["table.foreign_key.on_delete"], "SET_NULL")
+	require.Contains(t, enums["table.foreign_key.on_update"], "CASCADE")
+	// The returned map is a copy.
+	enums["table.index.type"][0] = "INVALID"
+	require.Equal(t, IndexTypeBTree, ScopedEnums()["table.index.type"][0])
+}
+
+func TestSpec_NationalTypes(t *testing.T) {
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "a" {
+    type = nchar(10)
+  }
+  column "b" {
+    type = nvarchar(20)
+  }
+  column "c" {
+    type    = nvarchar(20)
+    charset = "utf8mb4"
+  }
+}
+`), &s, nil))
+	cols := s.Tables[0].Columns
+	require.Equal(t, &schema.StringType{T: TypeChar, Size: 10}, cols[0].Type.Type)
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8mb3"}}, cols[0].Attrs)
+	require.Equal(t, &schema.StringType{T: TypeVarchar, Size: 20}, cols[1].Type.Type)
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8mb3"}}, cols[1].Attrs)
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8mb4"}}, cols[2].Attrs)
+
+	// National types are normalized to their CHAR and VARCHAR forms.
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  column "a" {
+    null    = false
+    type    = char(10)
+    charset = "utf8mb3"
+  }`)
+	require.Contains(t, string(buf), `  column "b" {
+    null    = false
+    type    = varchar(20)
+    charset = "utf8mb3"
+  }`)
+
+	for raw, expected := range map[string]schema.Type{
+		"nchar(10)":            &schema.StringType{T: TypeChar, Size: 10},
+		"nvarchar(20)":         &schema.StringType{T: TypeVarchar, Size: 20},
+		"national char(10)":    &schema.StringType{T: TypeChar, Size: 10},
+		"NATIONAL varchar(20)": &schema.StringType{T: TypeVarchar, Size: 20},
+	} {
+		typ, err := ParseType(raw)
+		require.NoError(t, err)
+		require.Equal(t, expected, typ, raw)
+	}
+}
+
+func TestSpec_Serial(t *testing.T) {
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  column "seq" {
+    type = serial
+  }
+  column "name" {
+    type = varchar(255)
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "seq" {
+    columns = [column.name]
+  }
+}
+table "u" {
+  schema = schema.test
+  column "id" {
+    type = serial
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+`), &s, nil))
+	tbl := s.Tables[0]
+	c := tbl.Columns[1]
+	require.Equal(t, &schema.IntegerType{T: TypeBigInt, Unsigned: true}, c.Type.Type)
+	require.False(t, c.Type.Null)
+	require.Equal(t, []schema.Attr{&AutoIncrement{}}, c.Attrs)
+	// The implicit index is suffixed, as its name is taken by another index.
+	require.Len(t, tbl.Indexes, 2)
+	require.True(t, tbl.Indexes[1].Unique)
+	require.Equal(t, "seq_2", tbl.Indexes[1].Name)
+	require.Equal(t, c, tbl.Indexes[1].Parts[0].C)
+	require.Equal(t, tbl, tbl.Indexes[1].Table)
+	// The primary key covers the "id" column.
+	require.Empty(t, s.Tables[1].Indexes)
+	require.Equal(t, []schema.Attr{&AutoIncrement{}}, s.Tables[1].Columns[0].Attrs)
+
+	// The expanded form is marshaled.
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  column "seq" {
+    null           = false
+    type           = bigint
+    unsigned       = true
+    auto_increment = true
+  }`)
+	require.Contains(t, string(buf), `  index "seq_2" {
+    unique  = true
+    columns = [column.seq]
+  }`)
+
+	// The expanded form is collapsed back, and evaluated to the same schema.
+	buf, err = MarshalHCLSerial(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  column "seq" {
+    null = false
+    type = serial
+  }`)
+	require.Contains(t, string(buf), `  column "id" {
+    null = false
+    type = serial
+  }`)
+	require.Contains(t, string(buf), `  column "id" {
+    null = false
+    type = int
+  }`)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	buf2, err := MarshalHCLSerial(&s2)
+	require.NoError(t, err)
+	require.Equal(t, string(buf), string(buf2))
+
+	// Columns that differ from the expansion are not collapsed.
+	c.Type.Null = true
+	buf, err = MarshalHCLSerial(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  column "seq" {
+    null           = true
+    type           = bigint
+    unsigned       = true
+    auto_increment = true
+  }`)
+
+	for _, tt := range []struct{ columns, err string }{
+		{
+			columns: `
+  column "id" {
+    type = serial
+    null = true
+  }`,
+			err: `column "id": type serial cannot be nullable`,
+		},
+		{
+			columns: `
+  column "id" {
+    type = serial
+  }
+  column "seq" {
+    type = serial
+  }`,
+			err: `table "t": serial column "id" cannot be used with another auto_increment column "seq"`,
+		},
+		{
+			columns: `
+  column "id" {
+    type           = int
+    auto_increment = true
+  }
+  column "seq" {
+    type = serial
+  }`,
+			err: `table "t": serial column "seq" cannot be used with another auto_increment column "id"`,
+		},
+	} {
+		err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test`+tt.columns+`
+}
+`), &s, nil)
+		require.ErrorContains(t, err, tt.err)
+	}
+}
+
+func TestSpec_Temporary(t *testing.T) {
+	const f = `table "tmp" {
+  schema    = schema.test
+  temporary = true
+  column "id" {
+    null = false
+    type = int
+  }
+}
+table "t" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&Temporary{}}, s.Tables[0].Attrs)
+	require.Empty(t, s.Tables[1].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+}
+
+func TestEvalHCLWith_StrictAttrs(t *testing.T) {
+	const f = `
+schema "test" {}
+table "t" {
+  schema  = schema.test
+  charset = "utf8mb4"
+  column "id" {
+    type           = int
+    unsigned       = true
+    auto_increment = true
+    comment        = "id"
+  }
+  column "name" {
+    type = varchar(255)
+    %s
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "name" {
+    type = BTREE
+    on {
+      column = column.name
+      prefix = 10
+    }
+  }
+  check {
+    expr     = "id > 0"
+    enforced = true
+  }
+}
+`
+	var (
+		s          schema.Schema
+		evalStrict = specutil.HCLBytesFunc(EvalHCLWith(WithStrictAttrs()))
+	)
+	require.NoError(t, evalStrict([]byte(fmt.Sprintf(f, "null = true")), &s, nil))
+	require.True(t, s.Tables[0].Columns[1].Type.Null)
+
+	// Misspelled attributes are ignored in the default mode.
+	require.NoError(t, EvalHCLBytes([]byte(fmt.Sprintf(f, "nul = true")), &s, nil))
+	require.False(t, s.Tables[0].Columns[1].Type.Null)
+	err := evalStrict([]byte(fmt.Sprintf(f, "nul = true")), &s, nil)
+	require.EqualError(t, err, `table "t": column "name": unknown attribute "nul"`)
+
+	// Options can be combined.
+	err = specutil.HCLBytesFunc(EvalHCLWith(WithStrictAttrs(), WithAllErrors()))([]byte(fmt.Sprintf(f, "nul = true\n    tpye = int")), &s, nil)
+	var all interface{ Errors() []error }
+	require.ErrorAs(t, err, &all)
+	require.Len(t, all.Errors(), 2)
+	require.EqualError(t, all.Errors()[0], `table "t": column "name": unknown attribute "nul"`)
+	require.EqualError(t, all.Errors()[1], `table "t": column "name": unknown attribute "tpye"`)
+}
+
+func TestSpec_IndexTypeColumns(t *testing.T) {
+	const f = `
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "c" {
+    type = %s
+    null = %t
+  }
+  index "i" {
+    type    = %s
+    columns = [column.c]
+  }
+}
+`
+	for _, tt := range []struct {
+		typ, idx string
+		null     bool
+		wantErr  string
+	}{
+		{typ: "text", idx: "FULLTEXT", null: true},
+		{typ: "varchar(255)", idx: "FULLTEXT"},
+		{typ: "point", idx: "SPATIAL"},
+		{typ: "int", idx: "FULLTEXT", wantErr: `index "i": FULLTEXT index requires char, varchar or text columns, but column "c" is of type int`},
+		{typ: "int", idx: "SPATIAL", null: true, wantErr: `index "i": SPATIAL index requires spatial columns, but column "c" is of type int`},
+		{typ: "geometry", idx: "SPATIAL", null: true, wantErr: `index "i": SPATIAL index requires NOT NULL columns, but column "c" is nullable`},
+	} {
+		t.Run(tt.typ+"/"+tt.idx, func(t *testing.T) {
+			var s schema.Schema
+			err := EvalHCLBytes([]byte(fmt.Sprintf(f, tt.typ, tt.null, tt.idx)), &s, nil)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []schema.Attr{&IndexType{T: tt.idx}}, s.Tables[0].Indexes[0].Attrs)
+		})
+	}
+}
+
+func TestSpec_SameIndexNameInTables(t *testing.T) {
+	t1 := schema.NewTable("t1").AddColumns(schema.NewIntColumn("a", TypeInt))
+	t1.AddIndexes(schema.NewIndex("idx").AddColumns(t1.Columns[0]))
+	t2 := schema.NewTable("t2").AddColumns(schema.NewIntColumn("b", TypeInt))
+	t2.AddIndexes(schema.NewUniqueIndex("idx").AddColumns(t2.Columns[0]))
+	s := schema.New("test").AddTables(t1, t2)
+	buf, err := MarshalHCL.MarshalSpec(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "t1" {
+  schema = schema.test
+  column "a" {
+    null = false
+    type = int
+  }
+  index "idx" {
+    columns = [column.a]
+  }
+}
+table "t2" {
+  schema = schema.test
+  column "b" {
+    null = false
+    type = int
+  }
+  index "idx" {
+    unique  = true
+    columns = [column.b]
+  }
+}
+schema "test" {
+}
+`, string(buf))
+
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	for i, tt := range []struct {
+		unique bool
+		column string
+	}{
+		{false, "a"},
+		{true, "b"},
+	} {
+		tbl := after.Tables[i]
+		require.Len(t, tbl.Indexes, 1)
+		idx := tbl.Indexes[0]
+		require.Equal(t, "idx", idx.Name)
+		require.Equal(t, tt.unique, idx.Unique)
+		require.Same(t, tbl, idx.Table)
+		require.Same(t, tbl.Columns[0], idx.Parts[0].C)
+		require.Equal(t, tt.column, idx.Parts[0].C.Name)
+	}
+}
+
+func TestSpec_Compressed(t *testing.T) {
+	f := `table "docs" {
+  schema = schema.test
+  column "body" {
+    null       = false
+    type       = blob
+    compressed = true
+  }
+  column "title" {
+    null = false
+    type = varchar(255)
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&Compressed{}}, s.Tables[0].Columns[0].Attrs)
+	require.Empty(t, s.Tables[0].Columns[1].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	for _, tt := range []struct {
+		version, comment, cmd, err string
+	}{
+		{
+			version: "8.0.28-19",
+			comment: "Percona Server (GPL), Release 19, Revision 31e88966cd3",
+			cmd:     "CREATE TABLE `test`.`docs` (`body` blob NOT NULL COLUMN_FORMAT COMPRESSED, `title` varchar(255) NOT NULL)",
+		},
+		{
+			version: "10.5.8-MariaDB",
+			comment: "mariadb.org binary distribution",
+			cmd:     "CREATE TABLE `test`.`docs` (`body` blob COMPRESSED NOT NULL, `title` varchar(255) NOT NULL)",
+		},
+		{
+			version: "8.0.19",
+			comment: "MySQL Community Server - GPL",
+			err:     `create table "docs": column "body": compressed columns are supported only by MariaDB and Percona Server`,
+		},
+	} {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.versionComment(tt.version, tt.comment)
+		drv, err := Open(db)
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tt.cmd, plan.Changes[0].Cmd)
+	}
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "c" {
+    type       = int
+    compressed = true
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `column "c": attribute "compressed" is supported only for string, binary and json types`)
+}
+
+func TestEvalHCLWith_EnvVars(t *testing.T) {
+	const f = `
+variable "charset" {
+  type = string
+}
+schema "s" {
+  charset = var.charset
+}
+table "t" {
+  schema = schema.s
+  column "id" {
+    type = int
+  }
+}
+`
+	t.Setenv("ATLAS_VAR_charset", "latin1")
+	var s schema.Schema
+	err := EvalHCLBytes([]byte(f), &s, nil)
+	require.EqualError(t, err, `missing value for required variable "charset"`)
+
+	eval := specutil.HCLBytesFunc(EvalHCLWith(schemahcl.WithEnvVars("ATLAS_VAR_")))
+	require.NoError(t, eval([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "latin1"}}, s.Attrs)
+	require.Equal(t, "t", s.Tables[0].Name)
+}
+
+func TestSpec_EnumValuesOrder(t *testing.T) {
+	f := `table "t" {
+  schema = schema.test
+  column "e" {
+    null = false
+    type = enum("b","a","c")
+  }
+  column "s" {
+    null = false
+    type = set("z","x","y")
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []string{"b", "a", "c"}, s.Tables[0].Columns[0].Type.Type.(*schema.EnumType).Values)
+	require.Equal(t, []string{"z", "x", "y"}, s.Tables[0].Columns[1].Type.Type.(*SetType).Values)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	typ, err := FormatType(s.Tables[0].Columns[0].Type.Type)
+	require.NoError(t, err)
+	require.Equal(t, "enum('b','a','c')", typ)
+	pt, err := ParseType("enum('b','a')")
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "a"}, pt.(*schema.EnumType).Values)
+	// Reordering the values is a type change.
+	to := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(
+			schema.NewEnumColumn("e", schema.EnumValues("a", "b", "c")),
+			s.Tables[0].Columns[1],
+		)
+	changes, err := DefaultDiff.TableDiff(s.Tables[0], to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.True(t, changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeType))
+}
+
+func TestMarshalSpec_EscapeStrings(t *testing.T) {
+	const comment = "a \"quoted\" \\backslash\\ with ${interpolation}, %{directive}\nand a newline\t"
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("t").
+				SetComment(comment).
+				AddColumns(
+					schema.NewIntColumn("id", TypeInt).SetComment(comment),
+				).
+				AddIndexes(
+					schema.NewIndex("id").AddColumns(schema.NewColumn("id")).AddAttrs(&schema.Comment{Text: comment}),
+				),
+		)
+	buf, err := MarshalHCL.MarshalSpec(s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `comment = "a \"quoted\" \\backslash\\ with $${interpolation}, %%{directive}\nand a newline\t"`)
+
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	tbl := after.Tables[0]
+	for _, attrs := range [][]schema.Attr{tbl.Attrs, tbl.Columns[0].Attrs, tbl.Indexes[0].Attrs} {
+		var c schema.Comment
+		require.True(t, sqlx.Has(attrs, &c))
+		require.Equal(t, comment, c.Text)
+	}
+}
+
+func TestSpec_DefaultNull(t *testing.T) {
+	f := `table "t" {
+  schema = schema.test
+  column "a" {
+    null    = true
+    type    = varchar(255)
+    default = null
+  }
+  column "b" {
+    null = true
+    type = varchar(255)
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, &schema.RawExpr{X: "NULL"}, s.Tables[0].Columns[0].Default)
+	require.Nil(t, s.Tables[0].Columns[1].Default)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`a` varchar(255) NULL DEFAULT NULL, `b` varchar(255) NULL)", plan.Changes[0].Cmd)
+
+	// The two forms are equivalent.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(
+			schema.NewNullStringColumn("a", TypeVarchar, schema.StringSize(255)),
+			schema.NewNullStringColumn("b", TypeVarchar, schema.StringSize(255)),
+		)
+	changes, err := DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	inspected.Columns[0].SetDefault(&schema.Literal{V: "'a'"})
+	changes, err = DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.True(t, changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeDefault))
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "a" {
+    type    = int
+    default = null
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `column "a": NOT NULL column cannot have a NULL default value`)
+}
+
+func TestSpec_DefaultKinds(t *testing.T) {
+	f := `table "t" {
+  schema = schema.test
+  column "s" {
+    null    = false
+    type    = varchar(255)
+    default = "hello"
+  }
+  column "n" {
+    null    = false
+    type    = varchar(255)
+    default = "42"
+  }
+  column "i" {
+    null    = false
+    type    = int
+    default = 42
+  }
+  column "b" {
+    null    = false
+    type    = bool
+    default = true
+  }
+  column "f" {
+    null    = false
+    type    = timestamp
+    default = sql("now()")
+  }
+  column "h" {
+    null    = false
+    type    = varchar(10)
+    default = "0x1F"
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	cols := s.Tables[0].Columns
+	require.Equal(t, &schema.Literal{V: `"hello"`}, cols[0].Default)
+	require.Equal(t, &schema.Literal{V: `"42"`}, cols[1].Default)
+	require.Equal(t, &schema.Literal{V: "42"}, cols[2].Default)
+	require.Equal(t, &schema.Literal{V: "true"}, cols[3].Default)
+	require.Equal(t, &schema.RawExpr{X: "now()"}, cols[4].Default)
+	require.Equal(t, &schema.Literal{V: `"0x1F"`}, cols[5].Default)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`s` varchar(255) NOT NULL DEFAULT \"hello\", `n` varchar(255) NOT NULL DEFAULT \"42\", `i` int NOT NULL DEFAULT 42, `b` bool NOT NULL DEFAULT true, `f` timestamp NOT NULL DEFAULT now(), `h` varchar(10) NOT NULL DEFAULT \"0x1F\")", plan.Changes[0].Cmd)
+
+	// Inspected string defaults are single-quoted.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(
+			schema.NewStringColumn("s", TypeVarchar, schema.StringSize(255)).SetDefault(&schema.Literal{V: "'hello'"}),
+			schema.NewStringColumn("n", TypeVarchar, schema.StringSize(255)).SetDefault(&schema.Literal{V: "'42'"}),
+		)
+	changes, err := DefaultDiff.TableDiff(inspected, schema.NewTable("t").SetSchema(schema.New("test")).AddColumns(cols[0], cols[1]))
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Hexadecimal literals are kept as-is only on binary types.
+	require.NoError(t, EvalHCLBytes([]byte(strings.Replace(f, "varchar(10)", "varbinary(10)", 1)), &s, nil))
+	require.Equal(t, &schema.Literal{V: "0x1F"}, s.Tables[0].Columns[5].Default)
+}
+
+func TestSpec_BoolDefault(t *testing.T) {
+	f := `schema "test" {}
+table "t" {
+  schema = schema.test
+  column "c" {
+    type    = bool
+    default = %s
+  }
+}
+`
+	for v, expected := range map[string]string{"true": "true", "1": "true", `"TRUE"`: "true", "false": "false", "0": "false"} {
+		var s schema.Schema
+		require.NoError(t, EvalHCLBytes([]byte(fmt.Sprintf(f, v)), &s, nil))
+		require.Equal(t, &schema.Literal{V: expected}, s.Tables[0].Columns[0].Default, v)
+		buf, err := MarshalHCL.MarshalSpec(&s)
+		require.NoError(t, err)
+		require.Contains(t, string(buf), "default = "+expected, v)
+
+		// Inspected tables report the default as 1 or 0.
+		inspected := schema.NewTable("t").
+			SetSchema(schema.New("test")).
+			AddColumns(schema.NewBoolColumn("c", TypeBool))
+		inspected.Columns[0].Default = &schema.Literal{V: map[string]string{"true": "1", "false": "0"}[expected]}
+		changes, err := DefaultDiff.TableDiff(inspected, s.Tables[0])
+		require.NoError(t, err)
+		require.Empty(t, changes, v)
+		buf2, err := MarshalHCL.MarshalSpec(inspected.Schema.AddTables(inspected))
+		require.NoError(t, err)
+		require.Equal(t, string(buf), string(buf2), v)
+	}
+}
+
+func TestEvalHCL_NormalizedNames(t *testing.T) {
+	f := []byte(`
+schema "app" {}
+table "Users" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+}
+table "Posts" {
+  schema = schema.app
+  column "author" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author]
+    ref_columns = [table.Users.column.id]
+  }
+}
+`)
+	inspected := schema.New("app").AddTables(
+		schema.NewTable("users").AddColumns(schema.NewIntColumn("id", TypeInt)),
+		schema.NewTable("posts").AddColumns(schema.NewIntColumn("author", TypeInt)),
+	)
+	inspected.Tables[1].AddForeignKeys(
+		schema.NewForeignKey("author").AddColumns(inspected.Tables[1].Columns[0]).SetRefTable(inspected.Tables[0]).AddRefColumns(inspected.Tables[0].Columns[0]),
+	)
+
+	// Case-sensitive.
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes(f, &s, nil))
+	changes, err := DefaultDiff.SchemaDiff(inspected, &s)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+
+	// Case-insensitive.
+	s = schema.Schema{}
+	require.NoError(t, specutil.HCLBytesFunc(EvalHCLWith(WithNormalizedNames(strings.ToLower)))(f, &s, nil))
+	require.Equal(t, "users", s.Tables[0].Name)
+	require.Equal(t, "posts", s.Tables[1].Name)
+	require.Equal(t, "users", s.Tables[1].ForeignKeys[0].RefTable.Name)
+	changes, err = DefaultDiff.SchemaDiff(inspected, &s)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	var r schema.Realm
+	require.NoError(t, specutil.HCLBytesFunc(EvalHCLWith(WithNormalizedNames(strings.ToUpper)))(f, &r, nil))
+	require.Equal(t, "APP", r.Schemas[0].Name)
+	require.Equal(t, "USERS", r.Schemas[0].Tables[0].Name)
+
+	err = specutil.HCLBytesFunc(EvalHCLWith(WithNormalizedNames(strings.ToLower)))([]byte(`
+schema "app" {}
+table "Users" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+}
+table "users" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `mysql: tables "Users" and "users" in schema "app" have the same normalized name "users"`)
+
+	// Combined with other evaluation options.
+	s = schema.Schema{}
+	err = specutil.HCLBytesFunc(EvalHCLWith(WithNormalizedNames(strings.ToLower), WithStrictAttrs()))([]byte(`
+schema "app" {}
+table "Users" {
+  schema = schema.app
+  column "ID" {
+    type = int
+  }
+}
+`), &s, nil)
+	require.NoError(t, err)
+	require.Equal(t, "users", s.Tables[0].Name)
+	require.Equal(t, "ID", s.Tables[0].Columns[0].Name, "column names are not normalized")
+	err = specutil.HCLBytesFunc(EvalHCLWith(WithNormalizedNames(strings.ToLower), WithStrictAttrs()))([]byte(`
+schema "app" {}
+table "Users" {
+  schema = schema.app
+  column "id" {
+    type = int
+    nul  = true
+  }
+}
+`), &s, nil)
+	require.Error(t, err)
+}
+
+func TestSchemaHash(t *testing.T) {
+	newSchema := func(reverse bool) *schema.Schema {
+		users := schema.NewTable("users").
+			AddColumns(
+				schema.NewIntColumn("id", TypeInt),
+				schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)),
+			)
+		users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+		indexes := []*schema.Index{
+			schema.NewIndex("by_name").AddColumns(users.Columns[1]),
+			schema.NewUniqueIndex("by_id_name").AddColumns(users.Columns[0], users.Columns[1]),
+		}
+		checks := []*schema.Check{
+			schema.NewCheck().SetName("id_positive").SetExpr("id > 0"),
+			schema.NewCheck().SetName("name_not_empty").SetExpr("name <> ''"),
+		}
+		posts := schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", TypeInt))
+		tables := []*schema.Table{users, posts}
+		if reverse {
+			indexes[0], indexes[1] = indexes[1], indexes[0]
+			checks[0], checks[1] = checks[1], checks[0]
+			tables[0], tables[1] = tables[1], tables[0]
+			users.SetComment("registered users")
+		}
+		users.AddIndexes(indexes...).AddChecks(checks...)
+		return schema.New("test").AddTables(tables...)
+	}
+	s1, s2 := newSchema(false), newSchema(true)
+	h1, err := SchemaHash(s1)
+	require.NoError(t, err)
+	require.Len(t, h1, 64)
+	h2, err := SchemaHash(s2)
+	require.NoError(t, err)
+	require.Equal(t, h1, h2)
+	// The given schema is not modified.
+	require.Equal(t, "posts", s2.Tables[0].Name)
+	require.Equal(t, "by_id_name", s2.Tables[1].Indexes[0].Name)
+
+	s2.Tables[1].Columns[1].Type.Null = true
+	h2, err = SchemaHash(s2)
+	require.NoError(t, err)
+	require.NotEqual(t, h1, h2)
+}
+
+func TestMarshalSpec_IndexPartsOrder(t *testing.T) {
+	tbl := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(
+			schema.NewIntColumn("a", TypeInt),
+			schema.NewIntColumn("b", TypeInt),
+			schema.NewIntColumn("c", TypeInt),
+		)
+	tbl.AddIndexes(
+		schema.NewIndex("idx").
+			AddParts(
+				schema.NewColumnPart(tbl.Columns[0]),
+				schema.NewColumnPart(tbl.Columns[1]).SetDesc(true),
+				schema.NewColumnPart(tbl.Columns[2]).SetDesc(true),
+			),
+	)
+	tbl.Schema.AddTables(tbl)
+	buf, err := MarshalHCL.MarshalSpec(tbl.Schema)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  index "idx" {
+    on {
+      column = column.a
+    }
+    on {
+      desc   = true
+      column = column.b
+    }
+    on {
+      desc   = true
+      column = column.c
+    }
+  }`)
+
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s, nil))
+	idx, ok := s.Tables[0].Index("idx")
+	require.True(t, ok)
+	require.Len(t, idx.Parts, 3)
+	for i, p := range idx.Parts {
+		require.Equal(t, i, p.SeqNo)
+		require.Equal(t, tbl.Indexes[0].Parts[i].C.Name, p.C.Name)
+		require.Equal(t, tbl.Indexes[0].Parts[i].Desc, p.Desc)
+	}
+	changes, err := DefaultDiff.TableDiff(tbl, s.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestSchemaNames(t *testing.T) {
+	names, err := SchemaNames([]byte(`
+schema "app" {}
+schema "audit" {
+  charset = "utf8mb4"
+}
+table "users" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+}
+table "logs" {
+  schema = schema.audit
+  column "id" {
+    type = int
+  }
+}
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"app", "audit"}, names)
+
+	names, err = SchemaNames(nil)
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	_, err = SchemaNames([]byte(`schema "app" {`))
+	require.Error(t, err)
+}
+
+func TestSpec_VirtualColumnIndexVsFunctionalIndex(t *testing.T) {
+	f := `table "virtual" {
+  schema = schema.test
+  column "a" {
+    null = false
+    type = int
+  }
+  column "v" {
+    null = true
+    type = int
+    as {
+      expr = "(` + "`a`" + ` * 2)"
+      type = VIRTUAL
+    }
+  }
+  index "idx" {
+    columns = [column.v]
+  }
+}
+table "functional" {
+  schema = schema.test
+  column "a" {
+    null = false
+    type = int
+  }
+  index "idx" {
+    on {
+      expr = "(` + "`a`" + ` * 2)"
+    }
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	virtual, functional := s.Tables[0], s.Tables[1]
+	require.Len(t, virtual.Columns, 2)
+	require.Equal(t, "v", virtual.Indexes[0].Parts[0].C.Name)
+	require.Nil(t, virtual.Indexes[0].Parts[0].X)
+	require.Len(t, functional.Columns, 1, "functional indexes do not add columns")
+	require.Nil(t, functional.Indexes[0].Parts[0].C)
+	require.Equal(t, &schema.RawExpr{X: "(`a` * 2)"}, functional.Indexes[0].Parts[0].X)
+
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	for i, tt := range s.Tables {
+		changes, err := DefaultDiff.TableDiff(tt, got.Tables[i])
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	}
+
+	// The two forms are not interchangeable.
+	functional.Name = virtual.Name
+	changes, err := DefaultDiff.TableDiff(virtual, functional)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.IsType(t, &schema.DropColumn{}, changes[0])
+	require.IsType(t, &schema.ModifyIndex{}, changes[1])
+}
+
+func TestSpec_IdentLen(t *testing.T) {
+	f := `
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column %q {
+    type = int
+  }
+}
+`
+	var s schema.Schema
+	name := strings.Repeat("c", 64)
+	require.NoError(t, EvalHCLBytes([]byte(fmt.Sprintf(f, name)), &s, nil))
+	require.Equal(t, name, s.Tables[0].Columns[0].Name)
+
+	name += "c"
+	err := EvalHCLBytes([]byte(fmt.Sprintf(f, name)), &s, nil)
+	require.EqualError(t, err, fmt.Sprintf("column name %q of table \"t\" is too long (65 characters), maximum is 64", name))
+	var r schema.Realm
+	err = EvalHCLBytes([]byte(fmt.Sprintf(f, name)), &r, nil)
+	require.EqualError(t, err, fmt.Sprintf("mysql: failed converting to *schema.Realm: column name %q of table \"t\" is too long (65 characters), maximum is 64", name))
+
+	err = EvalHCLBytes([]byte(fmt.Sprintf(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "c" {
+    type = int
+  }
+  index %q {
+    columns = [column.c]
+  }
+}
+`, name)), &s, nil)
+	require.EqualError(t, err, fmt.Sprintf("index name %q of table \"t\" is too long (65 characters), maximum is 64", name))
+}
+
+func TestSpec_IndexMissingColumn(t *testing.T) {
+	tbl := schema.NewTable("users").AddColumns(schema.NewIntColumn("name", "int"))
+	_, err := convertIndex(&sqlspec.Index{
+		Name:    "idx_name",
+		Columns: []*schemahcl.Ref{{V: "$column.nmae"}},
+	}, tbl)
+	require.EqualError(t, err, `index "idx_name" of table "users": specutil: unknown column "nmae" in table "users"`)
+	_, err = convertIndex(&sqlspec.Index{
+		Name: "idx_name",
+		Parts: []*sqlspec.IndexPart{
+			{Column: &schemahcl.Ref{V: "$column.name"}},
+			{Column: &schemahcl.Ref{V: "$column.nmae"}},
+		},
+	}, tbl)
+	require.EqualError(t, err, `index "idx_name" of table "users": specutil: unknown column "nmae" in table "users"`)
+	idx, err := convertIndex(&sqlspec.Index{
+		Name: "idx_name",
+		Parts: []*sqlspec.IndexPart{
+			{Column: &schemahcl.Ref{V: "$column.name"}},
+			{Expr: "lower(name)"},
+		},
+	}, tbl)
+	require.NoError(t, err)
+	require.Len(t, idx.Parts, 2)
+
+	// Columns of other tables are not part of the indexed table.
+	var s schema.Schema
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "name" {
+    type = int
+  }
+  index "idx_email" {
+    columns = [table.emails.column.email]
+  }
+}
+table "emails" {
+  schema = schema.test
+  column "email" {
+    type = int
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `index "idx_email" of table "users": specutil: unknown column "email" in table "users"`)
+}
+
+func TestSpec_Encryption(t *testing.T) {
+	f := `table "t" {
+  schema     = schema.test
+  encryption = "Y"
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&Encryption{V: true}}, s.Tables[0].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`id` int NOT NULL) ENCRYPTION \"Y\"", plan.Changes[0].Cmd)
+
+	// Inspected tables report the option in their CREATE_OPTIONS.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(schema.NewIntColumn("id", TypeInt))
+	changes, err := DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: &Encryption{}, To: &Encryption{V: true}}}, changes)
+	plan, err = pl.PlanChanges(context.Background(), "", []schema.Change{&schema.ModifyTable{T: s.Tables[0], Changes: changes}})
+	require.NoError(t, err)
+	require.Equal(t, "ALTER TABLE `test`.`t` ENCRYPTION \"Y\"", plan.Changes[0].Cmd)
+	inspected.AddAttrs(&CreateOptions{V: "ENCRYPTION='Y'"})
+	changes, err = DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema     = schema.test
+  encryption = "yes"
+  column "id" {
+    type = int
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `table "t": invalid encryption value "yes", expected "Y" or "N"`)
+}
+
+func TestSpec_Compression(t *testing.T) {
+	f := `table "t" {
+  schema      = schema.test
+  compression = "zlib"
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&Compression{V: "zlib"}}, s.Tables[0].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`id` int NOT NULL) COMPRESSION \"zlib\"", plan.Changes[0].Cmd)
+
+	// Inspected tables report the option in their CREATE_OPTIONS.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(schema.NewIntColumn("id", TypeInt))
+	changes, err := DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: &Compression{V: "none"}, To: &Compression{V: "zlib"}}}, changes)
+	plan, err = pl.PlanChanges(context.Background(), "", []schema.Change{&schema.ModifyTable{T: s.Tables[0], Changes: changes}})
+	require.NoError(t, err)
+	require.Equal(t, "ALTER TABLE `test`.`t` COMPRESSION \"zlib\"", plan.Changes[0].Cmd)
+	inspected.AddAttrs(&CreateOptions{V: `COMPRESSION="ZLIB"`})
+	changes, err = DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema      = schema.test
+  compression = "zstd"
+  column "id" {
+    type = int
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `table "t": invalid compression value "zstd", expected "zlib", "lz4" or "none"`)
+}
+
+func TestSpec_BinaryDefaults(t *testing.T) {
+	f := `table "t" {
+  schema = schema.test
+  column "a" {
+    null    = false
+    type    = binary(4)
+    default = sql("0x00FF10AB")
+  }
+  column "b" {
+    null    = false
+    type    = binary(16)
+    default = sql("(uuid_to_bin(uuid()))")
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, &schema.RawExpr{X: "0x00FF10AB"}, s.Tables[0].Columns[0].Default)
+	require.Equal(t, &schema.RawExpr{X: "(uuid_to_bin(uuid()))"}, s.Tables[0].Columns[1].Default)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	// Hex string literals and function calls are normalized.
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(strings.NewReplacer(`0x00FF10AB`, `X'00FF10AB'`, `(uuid_to_bin(uuid()))`, `uuid_to_bin(uuid())`).Replace(f)), &s2, nil))
+	require.Equal(t, s.Tables[0].Columns[0].Default, s2.Tables[0].Columns[0].Default)
+	require.Equal(t, s.Tables[0].Columns[1].Default, s2.Tables[0].Columns[1].Default)
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s2.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`a` binary(4) NOT NULL DEFAULT 0x00FF10AB, `b` binary(16) NOT NULL DEFAULT (uuid_to_bin(uuid())))", plan.Changes[0].Cmd)
+
+	// Inspected defaults are reported in lowercase hex, and with parens for expressions.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(
+			schema.NewBinaryColumn("a", TypeBinary, schema.BinarySize(4)).SetDefault(&schema.Literal{V: "0x00ff10ab"}),
+			schema.NewBinaryColumn("b", TypeBinary, schema.BinarySize(16)).SetDefault(&schema.RawExpr{X: "(uuid_to_bin(uuid()))"}),
+		)
+	changes, err := DefaultDiff.TableDiff(inspected, s2.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_AttrsOrder(t *testing.T) {
+	newSchema := func(reverse bool) *schema.Schema {
+		tattrs := []schema.Attr{
+			&schema.Comment{Text: "users"},
+			&schema.Charset{V: "utf8mb4"},
+			&schema.Collation{V: "utf8mb4_bin"},
+			&StatsPersistent{V: "1"},
+			&StatsAutoRecalc{V: "0"},
+			&MaxRows{V: 100},
+			&MinRows{V: 1},
+			&UnknownAttr{A: schemahcl.StringAttr("b_option", "b")},
+			&UnknownAttr{A: schemahcl.StringAttr("a_option", "a")},
+		}
+		cattrs := []schema.Attr{
+			&schema.Comment{Text: "updated"},
+			&schema.Charset{V: "latin1"},
+			&schema.Collation{V: "latin1_bin"},
+			&OnUpdate{A: "CURRENT_TIMESTAMP"},
+			&UnknownAttr{A: schemahcl.StringAttr("y_option", "y")},
+			&UnknownAttr{A: schemahcl.StringAttr("x_option", "x")},
+		}
+		if reverse {
+			for i, j := 0, len(tattrs)-1; i < j; i, j = i+1, j-1 {
+				tattrs[i], tattrs[j] = tattrs[j], tattrs[i]
+			}
+			for i, j := 0, len(cattrs)-1; i < j; i, j = i+1, j-1 {
+				cattrs[i], cattrs[j] = cattrs[j], cattrs[i]
+			}
+		}
+		c := schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)).AddAttrs(cattrs...)
+		return schema.New("test").AddTables(schema.NewTable("users").AddColumns(c).AddAttrs(tattrs...))
+	}
+	b1, err := MarshalHCL.MarshalSpec(newSchema(false))
+	require.NoError(t, err)
+	b2, err := MarshalHCL.MarshalSpec(newSchema(true))
+	require.NoError(t, err)
+	require.Equal(t, string(b1), string(b2))
+	require.Equal(t, `table "users" {
+  schema            = schema.test
+  comment           = "users"
+  charset           = "utf8mb4"
+  collate           = "utf8mb4_bin"
+  stats_persistent  = 1
+  stats_auto_recalc = 0
+  max_rows          = 100
+  min_rows          = 1
+  a_option          = "a"
+  b_option          = "b"
+  column "name" {
+    null      = false
+    type      = varchar(255)
+    comment   = "updated"
+    charset   = "latin1"
+    collate   = "latin1_bin"
+    on_update = sql("CURRENT_TIMESTAMP")
+    x_option  = "x"
+    y_option  = "y"
+  }
+}
+schema "test" {
+}
+`, string(b1))
+}
+
+func TestSpec_IndexComment(t *testing.T) {
+	f := `table "t" {
+  schema = schema.test
+  column "name" {
+    null = false
+    type = varchar(255)
+  }
+  index "name" {
+    columns = [column.name]
+    comment = "it's a \"quoted\" comment"
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	idx, ok := s.Tables[0].Index("name")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: `it's a "quoted" comment`}}, idx.Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`name` varchar(255) NOT NULL, INDEX `name` (`name`) COMMENT \"it's a \\\"quoted\\\" comment\")", plan.Changes[0].Cmd)
+
+	// Inspected indexes with the same comment are not changed.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(schema.NewStringColumn("name", TypeVarchar, schema.StringSize(255)))
+	inspected.AddIndexes(schema.NewIndex("name").AddColumns(inspected.Columns[0]).AddAttrs(&schema.Comment{Text: `it's a "quoted" comment`}))
+	changes, err := DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestSpec_StatsOptions(t *testing.T) {
+	for _, tt := range []struct {
+		hcl  string
+		attr schema.Attr
+		cmd  string
+	}{
+		{hcl: "stats_persistent = 1", attr: &StatsPersistent{V: "1"}, cmd: "STATS_PERSISTENT 1"},
+		{hcl: `stats_persistent = "DEFAULT"`, attr: &StatsPersistent{V: "DEFAULT"}, cmd: "STATS_PERSISTENT DEFAULT"},
+		{hcl: "stats_auto_recalc = 0", attr: &StatsAutoRecalc{V: "0"}, cmd: "STATS_AUTO_RECALC 0"},
+		{hcl: "stats_sample_pages = 25", attr: &StatsSamplePages{V: "25"}, cmd: "STATS_SAMPLE_PAGES 25"},
+	} {
+		t.Run(tt.hcl, func(t *testing.T) {
+			f := fmt.Sprintf(`table "t" {
+  schema = schema.test
+  %s
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`, tt.hcl)
+			var s schema.Schema
+			require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+			require.Equal(t, []schema.Attr{tt.attr}, s.Tables[0].Attrs)
+			buf, err := MarshalHCL.MarshalSpec(&s)
+			require.NoError(t, err)
+			require.Equal(t, string(hclwrite.Format([]byte(f))), string(buf))
+
+			pl, _, err := newMigrate("8.0.19")
+			require.NoError(t, err)
+			plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+			require.NoError(t, err)
+			require.Equal(t, "CREATE TABLE `test`.`t` (`id` int NOT NULL) "+tt.cmd, plan.Changes[0].Cmd)
+		})
+	}
+
+	// Options are compared with the inspected CREATE_OPTIONS.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(schema.NewIntColumn("id", TypeInt)).
+		AddAttrs(&CreateOptions{V: "stats_persistent=1 stats_sample_pages=10"})
+	desired := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(schema.NewIntColumn("id", TypeInt)).
+		AddAttrs(&StatsPersistent{V: "1"}, &StatsSamplePages{V: "20"}, &StatsAutoRecalc{V: "DEFAULT"})
+	changes, err := DefaultDiff.TableDiff(inspected, desired)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: &StatsSamplePages{V: "10"}, To: &StatsSamplePages{V: "20"}}}, changes)
+
+	for hcl, msg := range map[string]string{
+		"stats_persistent = 2":     `table "t": invalid stats_persistent value "2", expected DEFAULT, 0 or 1`,
+		`stats_auto_recalc = "ON"`: `table "t": invalid stats_auto_recalc value "ON", expected DEFAULT, 0 or 1`,
+		"stats_sample_pages = 0":   `table "t": invalid stats_sample_pages value "0", expected DEFAULT or a positive number`,
+	} {
+		var s schema.Schema
+		err := EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  `+hcl+`
+  column "id" {
+    type = int
+  }
+}
+`), &s, nil)
+		require.EqualError(t, err, msg)
+	}
+}
+
+func TestSpec_RowsOptions(t *testing.T) {
+	f := `table "t" {
+  schema         = schema.test
+  avg_row_length = 256
+  max_rows       = 1000000
+  min_rows       = 10
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&AvgRowLength{V: 256}, &MaxRows{V: 1000000}, &MinRows{V: 10}}, s.Tables[0].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`id` int NOT NULL) AVG_ROW_LENGTH 256 MAX_ROWS 1000000 MIN_ROWS 10", plan.Changes[0].Cmd)
+
+	// Options are compared with the inspected CREATE_OPTIONS.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(schema.NewIntColumn("id", TypeInt)).
+		AddAttrs(&CreateOptions{V: "avg_row_length=256 max_rows=100"})
+	changes, err := DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyAttr{From: &MaxRows{V: 100}, To: &MaxRows{V: 1000000}},
+		&schema.ModifyAttr{From: &MinRows{V: 0}, To: &MinRows{V: 10}},
+	}, changes)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema   = schema.test
+  max_rows = -1
+  column "id" {
+    type = int
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `table "t": invalid max_rows value -1, expected a non-negative number`)
+}
+
+func TestSpec_PrimaryKeyPrefix(t *testing.T) {
+	f := `table "t" {
+  schema = schema.test
+  column "name" {
+    null = false
+    type = varchar(255)
+  }
+  column "id" {
+    null = false
+    type = int
+  }
+  primary_key {
+    on {
+      column = column.name
+      prefix = 10
+    }
+    on {
+      column = column.id
+    }
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	pk := s.Tables[0].PrimaryKey
+	require.Len(t, pk.Parts, 2)
+	require.Equal(t, "name", pk.Parts[0].C.Name)
+	require.Equal(t, []schema.Attr{&SubPart{Len: 10}}, pk.Parts[0].Attrs)
+	require.Equal(t, "id", pk.Parts[1].C.Name)
+	require.Empty(t, pk.Parts[1].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`name` varchar(255) NOT NULL, `id` int NOT NULL, PRIMARY KEY (`name` (10), `id`))", plan.Changes[0].Cmd)
+
+	// Primary keys without prefixes are marshaled using the "columns" attribute.
+	pk.Parts[0].Attrs = nil
+	buf, err = MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), "  primary_key {\n    columns = [column.name, column.id]\n  }\n")
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "name" {
+    type = varchar(255)
+  }
+  primary_key {
+    on {
+      column = column.name
+      prefix = 0
+    }
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `index "PRIMARY": attribute "prefix" must be a positive number, got 0 at position 0 (omit it to index the full column)`)
+}
+
+func TestSpec_NamespacedType(t *testing.T) {
+	f := `table "t" {
+  schema = schema.test
+  column "a" {
+    null = false
+    type = int
+  }
+  column "b" {
+    null = false
+    type = app.int
+  }
+}
+schema "test" {
+}
+`
+	// Namespaced types do not collide with the builtin types.
+	opt := schemahcl.WithTypes(append(TypeRegistry.Specs(), schemahcl.NewTypeSpec("app.int")))
+	var s schema.Schema
+	require.NoError(t, specutil.HCLBytesFunc(EvalHCLWith(opt))([]byte(f), &s, nil))
+	require.Equal(t, &schema.IntegerType{T: TypeInt}, s.Tables[0].Columns[0].Type.Type)
+	require.Equal(t, &schema.UnsupportedType{T: "app.int"}, s.Tables[0].Columns[1].Type.Type)
+	buf, err := MarshalHCLWith(opt).MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	// The namespace is unknown without the type.
+	err = EvalHCLBytes([]byte(f), &s, nil)
+	require.Error(t, err)
+}
+
+func TestEvalHCLDiagnostics(t *testing.T) {
+	var s schema.Schema
+	diags, err := EvalHCLDiagnostics([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type = varchr(10)
+  }
+}
+`), &s)
+	require.Error(t, err)
+	require.Len(t, diags, 1)
+	require.Equal(t, "error", diags[0].Severity)
+	require.Equal(t, "Call to unknown function", diags[0].Summary)
+	require.Equal(t, hcl.Pos{Line: 6, Column: 12, Byte: 84}, diags[0].Range.Start)
+	require.Equal(t, hcl.Pos{Line: 6, Column: 18, Byte: 90}, diags[0].Range.End)
+	require.Equal(t, []string{"table", "users", "column", "id"}, diags[0].Path)
+
+	// Syntax errors.
+	diags, err = EvalHCLDiagnostics([]byte(`schema "test" {`), &s)
+	require.Error(t, err)
+	require.NotEmpty(t, diags)
+	require.Equal(t, "error", diags[0].Severity)
+	require.NotNil(t, diags[0].Range)
+
+	// Errors without a source range.
+	diags, err = EvalHCLDiagnostics([]byte(`
+schema "test" {}
+schema "other" {}
+`), &s)
+	require.Error(t, err)
+	require.Equal(t, []Diagnostic{{Severity: "error", Summary: err.Error()}}, diags)
+
+	diags, err = EvalHCLDiagnostics([]byte(`schema "test" {}`), &s)
+	require.NoError(t, err)
+	require.Empty(t, diags)
+}
+
+func TestMarshalSpec_ColumnsOrder(t *testing.T) {
+	tbl := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(
+			schema.NewStringColumn("z", TypeVarchar, schema.StringSize(10)),
+			schema.NewIntColumn("b", TypeInt),
+			schema.NewIntColumn("id", TypeBigInt),
+			schema.NewIntColumn("a", TypeInt),
+		)
+	tbl.SetPrimaryKey(schema.NewPrimaryKey(tbl.Columns[2]))
+	tbl.AddIndexes(schema.NewUniqueIndex("a_b").AddColumns(tbl.Columns[3], tbl.Columns[1]))
+	tbl.Schema.AddTables(tbl)
+	buf, err := MarshalHCL.MarshalSpec(tbl.Schema)
+	require.NoError(t, err)
+	pos := -1
+	for _, c := range tbl.Columns {
+		i := strings.Index(string(buf), fmt.Sprintf("  column %q {", c.Name))
+		require.Greater(t, i, pos, "column %q is out of order", c.Name)
+		pos = i
+	}
+
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s, nil))
+	for i, c := range s.Tables[0].Columns {
+		require.Equal(t, tbl.Columns[i].Name, c.Name)
+	}
+}
+
+func TestMarshalSpec_NonIdentNames(t *testing.T) {
+	f := `table "my table" {
+  schema = schema["my db"]
+  column "123abc" {
+    null = false
+    type = int
+  }
+  column "my col" {
+    null = false
+    type = int
+  }
+  primary_key {
+    columns = [column["123abc"]]
+  }
+  index "my index" {
+    columns = [column["my col"]]
+  }
+}
+table "2nd" {
+  schema = schema["my db"]
+  column "ref" {
+    null = false
+    type = int
+  }
+  foreign_key "my fk" {
+    columns     = [column.ref]
+    ref_columns = [table["my table"].column["123abc"]]
+    on_update   = NO_ACTION
+    on_delete   = NO_ACTION
+  }
+}
+schema "my db" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, "my db", s.Name)
+	t1, t2 := s.Tables[0], s.Tables[1]
+	require.Equal(t, "my table", t1.Name)
+	require.Equal(t, "123abc", t1.Columns[0].Name)
+	require.Equal(t, "my col", t1.Columns[1].Name)
+	require.Equal(t, t1.Columns[0], t1.PrimaryKey.Parts[0].C)
+	require.Equal(t, t1.Columns[1], t1.Indexes[0].Parts[0].C)
+	require.Equal(t, t1, t2.ForeignKeys[0].RefTable)
+	require.Equal(t, t1.Columns[0], t2.ForeignKeys[0].RefColumns[0])
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+}
+
+func TestSpec_Union(t *testing.T) {
+	f := `table "t" {
+  schema = schema.test
+  union  = ["t1", "t2"]
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&Union{T: []string{"t1", "t2"}}}, s.Tables[0].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`id` int NOT NULL) UNION (`t1`, `t2`)", plan.Changes[0].Cmd)
+
+	// The option is compared with the inspected CREATE TABLE statement.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(schema.NewIntColumn("id", TypeInt)).
+		AddAttrs(&CreateStmt{S: "CREATE TABLE `t` (`id` int NOT NULL) ENGINE=MRG_MyISAM DEFAULT CHARSET=utf8mb4 UNION=(`t1`,`t2`)"})
+	changes, err := DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	s.Tables[0].Attrs[0].(*Union).T = []string{"t1", "t2", "t3"}
+	changes, err = DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: &Union{T: []string{"t1", "t2"}}, To: &Union{T: []string{"t1", "t2", "t3"}}}}, changes)
+}
+
+func TestSpec_InsertMethod(t *testing.T) {
+	f := `table "t" {
+  schema        = schema.test
+  union         = ["t1", "t2"]
+  insert_method = "LAST"
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&Union{T: []string{"t1", "t2"}}, &InsertMethod{V: "LAST"}}, s.Tables[0].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`id` int NOT NULL) UNION (`t1`, `t2`) INSERT_METHOD LAST", plan.Changes[0].Cmd)
+
+	// The option is compared with the inspected CREATE TABLE statement.
+	inspected := schema.NewTable("t").
+		SetSchema(schema.New("test")).
+		AddColumns(schema.NewIntColumn("id", TypeInt)).
+		AddAttrs(&CreateStmt{S: "CREATE TABLE `t` (`id` int NOT NULL) ENGINE=MRG_MyISAM INSERT_METHOD=FIRST UNION=(`t1`,`t2`)"})
+	changes, err := DefaultDiff.TableDiff(inspected, s.Tables[0])
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: &InsertMethod{V: "FIRST"}, To: &InsertMethod{V: "LAST"}}}, changes)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema        = schema.test
+  insert_method = "MIDDLE"
+  column "id" {
+    type = int
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `table "t": invalid insert_method value "MIDDLE", expected "NO", "FIRST" or "LAST"`)
+}
+
+func TestSpec_MigrationHints(t *testing.T) {
+	f := `table "t" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  migration {
+    algorithm = INPLACE
+    lock      = NONE
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&MigrationHints{Algorithm: "INPLACE", Lock: "NONE"}}, s.Tables[0].Attrs)
+	buf, err := MarshalHCL.MarshalSpec(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	// Hints are metadata, and are not part of the table definition.
+	pl, _, err := newMigrate("8.0.19")
+	require.NoError(t, err)
+	plan, err := pl.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: s.Tables[0]}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `test`.`t` (`id` int NOT NULL)", plan.Changes[0].Cmd)
+	changes, err := DefaultDiff.TableDiff(schema.NewTable("t").SetSchema(schema.New("test")).AddColumns(schema.NewIntColumn("id", TypeInt)), s.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  migration {
+    algorithm = "instant"
+  }
+}
+`), &s, nil))
+	require.Equal(t, []schema.Attr{&MigrationHints{Algorithm: "INSTANT"}}, s.Tables[0].Attrs)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "t" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  migration {
+    lock = "ALL"
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `table "t": invalid migration lock "ALL", expected one of: DEFAULT, NONE, SHARED, EXCLUSIVE`)
+}
+
+func TestSpec_InvalidSchemaName(t *testing.T) {
+	for name, msg := range map[string]string{
+		"":                      `mysql: schema name cannot be empty`,
+		"a ":                    `mysql: schema name "a " cannot end with a space`,
+		strings.Repeat("s", 65): `mysql: schema name "` + strings.Repeat("s", 65) + `" is too long (65 characters), maximum is 64`,
+	} {
+		f := []byte(fmt.Sprintf(`schema %q {}`, name))
+		var s schema.Schema
+		require.EqualError(t, EvalHCLBytes(f, &s, nil), msg)
+		var r schema.Realm
+		require.EqualError(t, EvalHCLBytes(f, &r, nil), msg)
+	}
+	for _, name := range []string{"my-db_1", "a.b", "a/b", `a\b`} {
+		var s schema.Schema
+		require.NoError(t, EvalHCLBytes([]byte(fmt.Sprintf(`schema %q {}`, name)), &s, nil))
+		require.Equal(t, name, s.Name)
+	}
+}
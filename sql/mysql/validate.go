@@ -0,0 +1,184 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"errors"
+	"fmt"
+
+	"ariga.io/atlas/sql/internal/specutil"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/mysql/internal/mysqlversion"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValidateTypes validates that the types of all columns in the given Atlas HCL
+// document are supported by the driver, without evaluating the document or
+// connecting to a database. The unsupported types of all columns are returned
+// as a single error that implements the interface below.
+//
+//	interface {
+//		Errors() []error
+//	}
+func ValidateTypes(data []byte) error {
+	f, diag := hclsyntax.ParseConfig(data, "", hcl.InitialPos)
+	if diag.HasErrors() {
+		return diag
+	}
+	var errs specutil.Errors
+	for _, tb := range f.Body.(*hclsyntax.Body).Blocks {
+		if tb.Type != "table" || len(tb.Labels) == 0 {
+			continue
+		}
+		for _, cb := range tb.Body.Blocks {
+			a, ok := cb.Body.Attributes["type"]
+			if cb.Type != "column" || len(cb.Labels) == 0 || !ok {
+				continue
+			}
+			if err := validateType(a.Expr); err != nil {
+				errs = append(errs, fmt.Errorf("column %q.%q: %w", tb.Labels[len(tb.Labels)-1], cb.Labels[0], err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateType validates the given column type expression. It is either a
+// type identifier (e.g. int), a type function (e.g. varchar(255)), or a raw
+// type defined using the sql function (e.g. sql("int unsigned")).
+func validateType(x hclsyntax.Expression) error {
+	var name string
+	switch x := x.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		// Types that are defined using input variables or
+		// locals are known only when the document is evaluated.
+		if name = x.Traversal.RootName(); name == "var" || name == "local" {
+			return nil
+		}
+	case *hclsyntax.FunctionCallExpr:
+		if x.Name != "sql" {
+			name = x.Name
+			break
+		}
+		if len(x.Args) != 1 {
+			return fmt.Errorf("sql() expects a single argument, got %d", len(x.Args))
+		}
+		v, diag := x.Args[0].Value(nil)
+		if diag.HasErrors() || v.Type() != cty.String {
+			return errors.New("sql() expects a literal string argument")
+		}
+		_, err := ParseType(v.AsString())
+		return err
+	default:
+		return fmt.Errorf("unexpected type expression %T", x)
+	}
+	for _, s := range TypeRegistry.Specs() {
+		if s.Name == name {
+			return nil
+		}
+	}
+	_, err := ParseType(name)
+	return err
+}
+
+// UnsupportedTypes returns the names of the types registered in the TypeRegistry
+// that are not supported by the given server version. For example, "json" for
+// MySQL versions prior to 5.7.8.
+func UnsupportedTypes(version string) ([]string, error) {
+	since, err := mysqlversion.V(version).TypesSince()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range TypeRegistry.Specs() {
+		if v, ok := since[s.T]; ok && mysqlversion.V(version).LT(v) {
+			names = append(names, s.Name)
+		}
+	}
+	return names, nil
+}
+
+// ValidateApplyable validates that the given Atlas HCL document can be applied to an
+// empty database, without connecting to one. It checks that the types of all columns
+// are supported, that all foreign keys reference existing tables and columns, that
+// index and constraint names are unique in each table, and that auto-increment columns
+// are the first column of a key. References to undefined tables or columns fail the
+// evaluation of the document, and its error is returned as is. Other problems are
+// returned as a single error that implements the interface below.
+//
+//	interface {
+//		Errors() []error
+//	}
+func ValidateApplyable(data []byte) error {
+	// Types are validated first, as the
+	// document cannot be evaluated without them.
+	if err := ValidateTypes(data); err != nil {
+		return err
+	}
+	var r schema.Realm
+	if err := specutil.HCLBytesFunc(EvalHCLWith(WithAllErrors()))(data, &r, nil); err != nil {
+		return err
+	}
+	var errs specutil.Errors
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			errs = append(errs, applyableTable(t)...)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// applyableTable returns the problems that prevent the given table from being created.
+func applyableTable(t *schema.Table) (errs []error) {
+	indexes := make(map[string]bool, len(t.Indexes))
+	for _, idx := range t.Indexes {
+		if indexes[idx.Name] {
+			errs = append(errs, fmt.Errorf("table %q: duplicate index name %q", t.Name, idx.Name))
+		}
+		indexes[idx.Name] = true
+	}
+	// Foreign keys and checks share the same namespace.
+	constraints := make(map[string]bool, len(t.ForeignKeys))
+	addConstraint := func(name string) {
+		if name == "" {
+			return
+		}
+		if constraints[name] {
+			errs = append(errs, fmt.Errorf("table %q: duplicate constraint name %q", t.Name, name))
+		}
+		constraints[name] = true
+	}
+	for _, fk := range t.ForeignKeys {
+		addConstraint(fk.Symbol)
+	}
+	for _, a := range t.Attrs {
+		if c, ok := a.(*schema.Check); ok {
+			addConstraint(c.Name)
+		}
+	}
+	for _, c := range t.Columns {
+		if !sqlx.Has(c.Attrs, &AutoIncrement{}) {
+			continue
+		}
+		keyed := t.PrimaryKey != nil && len(t.PrimaryKey.Parts) > 0 && t.PrimaryKey.Parts[0].C == c
+		for i := 0; i < len(t.Indexes) && !keyed; i++ {
+			keyed = len(t.Indexes[i].Parts) > 0 && t.Indexes[i].Parts[0].C == c
+		}
+		if !keyed {
+			errs = append(errs, fmt.Errorf("table %q: auto-increment column %q must be the first column of a key", t.Name, c.Name))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,196 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTypes(t *testing.T) {
+	require.NoError(t, ValidateTypes([]byte(`
+schema "s" {}
+table "t" {
+  schema = schema.s
+  column "a" {
+    type = int
+  }
+  column "b" {
+    type = varchar(255)
+  }
+  column "c" {
+    type = sql("int unsigned")
+  }
+  column "d" {
+    type = enum("a", "b")
+  }
+}
+`)))
+	err := ValidateTypes([]byte(`
+schema "s" {}
+table "t" {
+  schema = schema.s
+  column "a" {
+    type = geography
+  }
+  column "b" {
+    type = int
+  }
+}
+table "s" "u" {
+  schema = schema.s
+  column "c" {
+    type = sql("geography(4326)")
+  }
+  column "d" {
+    type = varchr(255)
+  }
+}
+`))
+	require.EqualError(t, err, `column "t"."a": mysql: unknown type "geography"
+column "u"."c": mysql: unknown type "geography"
+column "u"."d": mysql: unknown type "varchr"; did you mean "varchar"?`)
+	require.Len(t, err.(interface{ Errors() []error }).Errors(), 3)
+
+	// Types defined using variables are known only on evaluation.
+	doc := []byte(`
+variable "col_type" {
+  type    = string
+  default = "int"
+}
+locals {
+  col_type = int
+}
+schema "s" {}
+table "t" {
+  schema = schema.s
+  column "a" {
+    type = var.col_type
+  }
+  column "b" {
+    type = local.col_type
+  }
+}
+`)
+	require.NoError(t, ValidateTypes(doc))
+	require.NoError(t, ValidateApplyable(bytes.ReplaceAll(doc, []byte("var.col_type"), []byte("local.col_type"))))
+}
+
+func TestValidateApplyable(t *testing.T) {
+	require.NoError(t, ValidateApplyable([]byte(`
+schema "s" {}
+table "users" {
+  schema = schema.s
+  column "id" {
+    type           = int
+    auto_increment = true
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+table "posts" {
+  schema = schema.s
+  column "id" {
+    type           = int
+    auto_increment = true
+  }
+  column "author_id" {
+    type = int
+  }
+  index "id" {
+    columns = [column.id, column.author_id]
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+  check "positive_author" {
+    expr = "author_id > 0"
+  }
+}
+`)))
+
+	// Invalid types.
+	err := ValidateApplyable([]byte(`
+schema "s" {}
+table "t" {
+  schema = schema.s
+  column "a" {
+    type = geography
+  }
+}
+`))
+	require.EqualError(t, err, `column "t"."a": mysql: unknown type "geography"`)
+
+	// Dangling foreign key references.
+	err = ValidateApplyable([]byte(`
+schema "s" {}
+table "t" {
+  schema = schema.s
+  column "id" {
+    type = int
+  }
+  foreign_key "fk" {
+    columns     = [column.id]
+    ref_columns = [table.users.column.id]
+  }
+}
+`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `does not have an attribute named "users"`)
+
+	// Duplicate names and non-keyed auto-increment columns.
+	err = ValidateApplyable([]byte(`
+schema "s" {}
+table "t" {
+  schema = schema.s
+  column "id" {
+    type           = int
+    auto_increment = true
+  }
+  column "c" {
+    type = int
+  }
+  index "idx" {
+    columns = [column.c, column.id]
+  }
+  index "idx" {
+    columns = [column.c]
+  }
+  foreign_key "c" {
+    columns     = [column.c]
+    ref_columns = [table.t.column.id]
+  }
+  check "c" {
+    expr = "c > 0"
+  }
+}
+`))
+	require.EqualError(t, err, `table "t": duplicate index name "idx"
+table "t": duplicate constraint name "c"
+table "t": auto-increment column "id" must be the first column of a key`)
+	require.Len(t, err.(interface{ Errors() []error }).Errors(), 3)
+}
+
+func TestUnsupportedTypes(t *testing.T) {
+	names, err := UnsupportedTypes("5.6.51")
+	require.NoError(t, err)
+	require.Equal(t, []string{TypeJSON}, names)
+
+	names, err = UnsupportedTypes("10.1.48-MariaDB")
+	require.NoError(t, err)
+	require.Equal(t, []string{TypeJSON}, names)
+
+	names, err = UnsupportedTypes("8.0.19")
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	names, err = UnsupportedTypes("10.5.8-MariaDB")
+	require.NoError(t, err)
+	require.Empty(t, names)
+}
@@ -42,6 +42,7 @@ type (
 	// PrimaryKey holds a specification for the primary key of a table.
 	PrimaryKey struct {
 		Columns []*schemahcl.Ref `spec:"columns"`
+		Parts   []*IndexPart     `spec:"on"`
 		schemahcl.DefaultExtension
 	}
 